@@ -1,67 +1,70 @@
 package main
 
 import (
-	"bufio"
-	"flag"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"mysort/internal/config"
-	"mysort/internal/sort"
+	"mysort/sort"
 )
 
-func readLines(r io.Reader) ([]string, error) {
-	scanner := bufio.NewScanner(r)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines, scanner.Err()
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
 
-func main() {
-	var cfg config.Config
+// run sorts as args say and returns the exit status: 1 for input -c finds
+// out of order, 2 for errors, which are printed on stderr
+func run(args []string) int {
+	cfg, err := sort.ParseConfig(args...)
+	if err != nil {
+		return fail(err)
+	}
 
-	flag.IntVar(&cfg.Column, "k", 0, "sort by column number (tab-separated)")
-	flag.StringVar(&cfg.Delimiter, "N", "\t", "use DELIM instead of TAB for field delimiter")
-	flag.BoolVar(&cfg.Numeric, "n", false, "compare according to string numerical value")
-	flag.BoolVar(&cfg.Reverse, "r", false, "reverse the result of comparisons")
-	flag.BoolVar(&cfg.Unique, "u", false, "output only the first of an equal run")
-	flag.BoolVar(&cfg.Month, "M", false, "compare by month name")
-	flag.BoolVar(&cfg.IgnoreBlanks, "b", false, "ignore trailing blanks")
-	flag.BoolVar(&cfg.CheckSorted, "c", false, "check whether input is sorted")
-	flag.BoolVar(&cfg.Human, "h", false, "compare human readable numbers (e.g. 2K, 1G)")
+	var output *sort.Output
+	if cfg.Output != "" {
+		output = sort.NewOutput(cfg.Output)
+	}
 
-	flag.Parse()
+	inputs, closeInputs, err := sort.OpenInputs(cfg, output, open)
+	if err != nil {
+		return fail(err)
+	}
+	defer closeInputs()
 
-	args := flag.Args()
-	var r io.Reader
+	// an interrupted sort still removes its temporary files
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if len(args) > 0 {
-		f, err := os.Open(args[0])
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		r = f
+	if output != nil {
+		err = output.Finish(sort.SortStream(ctx, output, cfg, inputs...))
 	} else {
-		r = os.Stdin
+		err = sort.SortStream(ctx, os.Stdout, cfg, inputs...)
 	}
-
-	lines, err := readLines(r)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	if errors.Is(err, sort.ErrNotSorted) {
+		if !cfg.Quiet {
+			fmt.Fprintln(os.Stderr, "sort:", err)
+		}
+		return 1
 	}
-
-	lines, err = sort.Sort(lines, cfg)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return fail(err)
 	}
+	return 0
+}
 
-	for _, line := range lines {
-		fmt.Println(line)
+// open opens the input at name, or stdin for "-"
+func open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return os.Stdin, nil
 	}
+	return os.Open(name)
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "sort:", err)
+	return 2
 }
@@ -0,0 +1,236 @@
+// Package collate orders text by the Unicode Collation Algorithm, with the
+// Default Unicode Collation Element Table 13.0.0 bundled in the binary.
+//
+// Strings are compared at three levels: base letters first, then accents,
+// then case, so "apfel" < "Apfel" < "Äpfel" < "apple" < "zebra" < "Zebra".
+// Variable elements such as spaces and punctuation are not ignored, as in
+// the root collation of CLDR, and strings are not normalized; the table
+// covers the precomposed characters.
+package collate
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:generate go run gen.go allkeys.txt
+
+//go:embed allkeys.gz
+var allkeys []byte
+
+// languages are those CLDR collates in the root order, without tailoring
+var languages = []string{"de", "en"}
+
+// Collator compares strings in the order of a language
+type Collator struct {
+	table *table
+}
+
+// New returns a Collator for a locale such as de, de_DE or de_DE.UTF-8
+func New(locale string) (*Collator, error) {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "_-.@"); i >= 0 {
+		language = language[:i]
+	}
+	if !slices.Contains(languages, language) {
+		return nil, fmt.Errorf("unsupported locale '%s'", locale)
+	}
+	return &Collator{table: loadTable()}, nil
+}
+
+// Compare compares a and b by their sort keys, and strings equal at every
+// level by their bytes
+func (c *Collator) Compare(a, b string) int {
+	if k := bytes.Compare(c.Key(a), c.Key(b)); k != 0 {
+		return k
+	}
+	return strings.Compare(a, b)
+}
+
+// Key returns the sort key of s: the primary weights of its collation
+// elements, then the secondary and the tertiary ones, each level ended by
+// a zero weight. Keys compare bytewise in the order of their strings.
+func (c *Collator) Key(s string) []byte {
+	elements := c.table.appendElements(nil, s)
+	key := make([]byte, 0, 6*len(elements)+4)
+	for level := range 3 {
+		if level > 0 {
+			key = append(key, 0, 0)
+		}
+		for _, e := range elements {
+			if e[level] != 0 {
+				key = binary.BigEndian.AppendUint16(key, e[level])
+			}
+		}
+	}
+	return key
+}
+
+// element is a collation element: primary, secondary and tertiary weights
+type element [3]uint16
+
+// table maps characters to their collation elements
+type table struct {
+	chars        map[rune][]element
+	contractions map[string][]element // sequences collated as one
+	longest      map[rune]int         // runes in the longest sequence a rune starts
+	implicit     []implicitRange
+}
+
+// implicitRange is a range of code points with implicit weights on base
+type implicitRange struct {
+	first, last rune
+	base        uint16
+}
+
+var loadTable = sync.OnceValue(func() *table {
+	t, err := parseTable(allkeys)
+	if err != nil {
+		panic("collate: " + err.Error())
+	}
+	return t
+})
+
+// parseTable parses the compressed table gen.go writes
+func parseTable(data []byte) (*table, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	t := &table{
+		chars:        make(map[rune][]element),
+		contractions: make(map[string][]element),
+		longest:      make(map[rune]int),
+	}
+
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		left, right, ok := strings.Cut(line, ";")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+
+		if ranges, ok := strings.CutPrefix(left, "@"); ok {
+			first, last, _ := strings.Cut(ranges, "..")
+			r := implicitRange{first: hexRune(first), last: hexRune(last), base: uint16(hexRune(right))}
+			t.implicit = append(t.implicit, r)
+			continue
+		}
+
+		var runes []rune
+		for _, point := range strings.Fields(left) {
+			runes = append(runes, hexRune(point))
+		}
+		var elements []element
+		for _, weights := range strings.Fields(right) {
+			var e element
+			for i, weight := range strings.SplitN(weights, ".", 3) {
+				e[i] = uint16(hexRune(weight))
+			}
+			elements = append(elements, e)
+		}
+
+		if len(runes) == 1 {
+			t.chars[runes[0]] = elements
+			continue
+		}
+		t.contractions[string(runes)] = elements
+		t.longest[runes[0]] = max(t.longest[runes[0]], len(runes))
+	}
+	return t, scanner.Err()
+}
+
+// hexRune parses a hex number of the table, which gen.go wrote valid
+func hexRune(hex string) rune {
+	n, _ := strconv.ParseUint(hex, 16, 32)
+	return rune(n)
+}
+
+// appendElements appends the collation elements of s to dst, taking the
+// longest sequence of the table at each character
+func (t *table) appendElements(dst []element, s string) []element {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if n := t.longest[r]; n > 0 {
+			if elements, width := t.contraction(s[i:], n); width > 0 {
+				dst = append(dst, elements...)
+				i += width
+				continue
+			}
+		}
+		dst = t.appendRune(dst, r)
+		i += size
+	}
+	return dst
+}
+
+// contraction returns the elements of the longest sequence of at most n
+// runes at the start of s and its length in bytes, 0 without one
+func (t *table) contraction(s string, n int) ([]element, int) {
+	// ends[k] is where the first k+1 runes end; sequences have two or more
+	ends := make([]int, 0, n)
+	for i := 0; i < len(s) && len(ends) < n; {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		ends = append(ends, i)
+	}
+	for k := len(ends) - 1; k > 0; k-- {
+		if elements, ok := t.contractions[s[:ends[k]]]; ok {
+			return elements, ends[k]
+		}
+	}
+	return nil, 0
+}
+
+// Hangul syllables are not in the table but collate as their jamo
+const (
+	hangulBase  = 0xAC00
+	hangulCount = 11172
+	leadBase    = 0x1100
+	vowelBase   = 0x1161
+	trailBase   = 0x11A7
+	vowelCount  = 21
+	trailCount  = 28
+)
+
+// appendRune appends the collation elements of r, from the table or else
+// derived as the algorithm specifies
+func (t *table) appendRune(dst []element, r rune) []element {
+	if elements, ok := t.chars[r]; ok {
+		return append(dst, elements...)
+	}
+
+	if s := r - hangulBase; 0 <= s && s < hangulCount {
+		dst = append(dst, t.chars[leadBase+s/(vowelCount*trailCount)]...)
+		dst = append(dst, t.chars[vowelBase+s%(vowelCount*trailCount)/trailCount]...)
+		if trail := s % trailCount; trail != 0 {
+			dst = append(dst, t.chars[trailBase+trail]...)
+		}
+		return dst
+	}
+
+	for _, ir := range t.implicit {
+		if ir.first <= r && r <= ir.last {
+			return append(dst, element{ir.base, 0x20, 0x2}, element{uint16(r-ir.first) | 0x8000, 0, 0})
+		}
+	}
+	base := uint16(0xFBC0)
+	if unicode.Is(unicode.Unified_Ideograph, r) {
+		base = 0xFB80
+		if 0x4E00 <= r && r <= 0x9FFF || 0xF900 <= r && r <= 0xFAFF {
+			base = 0xFB40
+		}
+	}
+	return append(dst, element{base + uint16(r>>15), 0x20, 0x2}, element{uint16(r&0x7FFF) | 0x8000, 0, 0})
+}
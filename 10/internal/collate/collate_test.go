@@ -0,0 +1,89 @@
+package collate_test
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mysort/internal/collate"
+)
+
+func TestNew(t *testing.T) {
+	for _, locale := range []string{"de", "de_DE", "de-AT", "de_DE.UTF-8", "de_DE@euro", "DE", "en_US.utf8"} {
+		_, err := collate.New(locale)
+		assert.NoError(t, err, locale)
+	}
+	for _, locale := range []string{"", "C", "sv_SE", "german"} {
+		_, err := collate.New(locale)
+		assert.Error(t, err, locale)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	c, err := collate.New("de")
+	require.NoError(t, err)
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "apple", b: "Zebra", want: -1},
+		{a: "apfel", b: "Apfel", want: -1},
+		{a: "Apfel", b: "Äpfel", want: -1},
+		{a: "Äpfel", b: "Apfelsine", want: -1},
+		{a: "Äpfel", b: "apple", want: -1},
+		{a: "zebra", b: "Zebra", want: -1},
+		{a: "Ähre", b: "Affe", want: 1},
+		{a: "Straße", b: "Strasse", want: 1},
+		{a: "Müller", b: "Mueller", want: 1},
+		{a: "Müller", b: "Mulde", want: 1},
+		{a: "ä", b: "ä", want: -1},
+		{a: "résumé", b: "resume", want: 1},
+		{a: "9", b: "10", want: 1},
+		{a: "a b", b: "ab", want: -1},
+		{a: "same", b: "same", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Compare(tt.a, tt.b))
+			assert.Equal(t, -tt.want, c.Compare(tt.b, tt.a))
+		})
+	}
+}
+
+// TestOrder sorts words of several scripts and, when it is installed,
+// checks the expected order with Perl's Unicode::Collate, which bundles
+// the same table
+func TestOrder(t *testing.T) {
+	want := []string{
+		" x", "_x", "-1", "1", "1.5", "10", "2",
+		"a", "A", "á", "ä", "Ä", "ab", "äb", "Affe", "Ähre", "apfel", "Apfel", "Äpfel", "Apfelsine",
+		"ss", "SS", "ß", "Sz", "zebra", "Zebra",
+		"ёж", "ель", "ив", "ий", "йод", "юг",
+		"가", "각", "나", "一", "丁", "丂", "𠀀",
+	}
+
+	got := slices.Clone(want)
+	slices.Reverse(got)
+	c, err := collate.New("de_DE.UTF-8")
+	require.NoError(t, err)
+	slices.SortFunc(got, c.Compare)
+	assert.Equal(t, want, got)
+
+	if exec.Command("perl", "-MUnicode::Collate", "-e", "").Run() != nil {
+		return
+	}
+	script := `use utf8; use open qw(:std :utf8); use Unicode::Collate;
+my $c = Unicode::Collate->new(normalization => undef, variable => "non-ignorable");
+chomp(my @lines = <STDIN>); print "$_\n" for $c->sort(@lines);`
+	cmd := exec.Command("perl", "-e", script)
+	cmd.Stdin = strings.NewReader(strings.Join(got, "\n") + "\n")
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(want, "\n")+"\n", string(out), "Perl order")
+}
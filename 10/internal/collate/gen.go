@@ -0,0 +1,117 @@
+//go:build ignore
+
+// gen converts allkeys.txt, the Default Unicode Collation Element Table of
+// https://www.unicode.org/Public/UCA/, into the table collate embeds:
+//
+//	go run gen.go allkeys.txt
+//
+// Each line of the table maps code points to collation elements, all in
+// hex: "e4;1fa2.20.2 0.2b.2". The implicit weight ranges are kept as
+// "@17000..18aff;fb00". The variable marks are dropped, as collate treats
+// variable elements like the others.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run gen.go allkeys.txt")
+	}
+	in, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer in.Close()
+
+	out, err := os.Create("allkeys.gz")
+	if err != nil {
+		log.Fatal(err)
+	}
+	zw, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := bufio.NewWriter(zw)
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "@version") {
+			continue
+		}
+		converted, err := convert(line)
+		if err != nil {
+			log.Fatalf("%q: %v", scanner.Text(), err)
+		}
+		fmt.Fprintln(w, converted)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// convert turns a line of allkeys.txt, without its comment, into a line of
+// the table
+func convert(line string) (string, error) {
+	if rest, ok := strings.CutPrefix(line, "@implicitweights"); ok {
+		ranges, base, ok := strings.Cut(rest, ";")
+		if !ok {
+			return "", fmt.Errorf("no base weight")
+		}
+		return "@" + strings.ToLower(strings.TrimSpace(ranges)) + ";" + compact(strings.TrimSpace(base)), nil
+	}
+
+	chars, elements, ok := strings.Cut(line, ";")
+	if !ok {
+		return "", fmt.Errorf("no collation elements")
+	}
+	var points []string
+	for _, point := range strings.Fields(chars) {
+		points = append(points, compact(point))
+	}
+
+	var weights []string
+	for _, element := range strings.Split(strings.TrimSpace(elements), "]") {
+		if element == "" {
+			continue
+		}
+		element = strings.TrimLeft(element, "[.*")
+		parts := strings.Split(element, ".")
+		if len(parts) != 3 {
+			return "", fmt.Errorf("collation element %q", element)
+		}
+		for i := range parts {
+			parts[i] = compact(parts[i])
+		}
+		weights = append(weights, strings.Join(parts, "."))
+	}
+	return strings.Join(points, " ") + ";" + strings.Join(weights, " "), nil
+}
+
+// compact drops the leading zeros of a hex number
+func compact(hex string) string {
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return strconv.FormatUint(n, 16)
+}
@@ -0,0 +1,43 @@
+package sort_test
+
+import (
+	"testing"
+
+	"mysort/sort"
+)
+
+// BenchmarkSort sorts 10^6 lines in memory with the keys of several
+// orders
+func BenchmarkSort(b *testing.B) {
+	lines := randomLines(1_000_000)
+
+	benchmarks := []struct {
+		name string
+		args []string
+	}{
+		{name: "lines"},
+		{name: "numeric", args: []string{"-n"}},
+		{name: "human", args: []string{"-h"}},
+		{name: "general", args: []string{"-g"}},
+		{name: "month", args: []string{"-M"}},
+		{name: "version", args: []string{"-V"}},
+		{name: "fields", args: []string{"-t", " ", "-k2,2", "-k1,1n"}},
+		{name: "fold case", args: []string{"-f", "-d"}},
+		{name: "locale", args: []string{"--locale", "de_DE"}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg, err := sort.ParseConfig(bm.args...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := sort.Sort(lines, cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package sort
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"getopt"
+)
+
+// Config of sorting
+type Config struct {
+	Keys         []Key  // -k, the whole line is the key when empty
+	Delimiter    string // -t, fields are separated by blanks when empty
+	Numeric      bool   // -n
+	Reverse      bool   // -r
+	Unique       bool   // -u
+	Month        bool   // -M
+	IgnoreBlanks bool   // -b
+	CheckSorted  bool   // -c
+	Quiet        bool   // -C, -c that reports disorder only by its error
+	Merge        bool   // -m, the inputs are sorted already
+	Output       string // -o, stdout when empty
+	Human        bool   // -h
+	Version      bool   // -V
+	General      bool   // -g
+	Stable       bool   // -s, lines with equal keys are not compared whole
+	TempDir      string // -T, the system's when empty
+	BufferSize   int64  // -S, in bytes, defaultBufferSize when 0
+	Parallel     int    // --parallel, the number of CPUs when 0
+
+	FoldCase          bool   // -f
+	Dictionary        bool   // -d
+	IgnoreNonprinting bool   // -i
+	Locale            string // --locale, text is ordered by its bytes when empty or C
+
+	ZeroTerminated bool   // -z, lines end with NUL instead of a newline
+	Files0From     string // --files0-from, the file with the names of the inputs
+
+	Files []string
+}
+
+// options of sort; -N is the name mysort first used for -t
+var options = []getopt.Option{
+	{Short: 'k', Long: "key", HasArg: true},
+	{Short: 't', Long: "field-separator", HasArg: true},
+	{Short: 'N', HasArg: true},
+	{Short: 'n', Long: "numeric-sort"},
+	{Short: 'r', Long: "reverse"},
+	{Short: 'u', Long: "unique"},
+	{Short: 'M', Long: "month-sort"},
+	{Short: 'b', Long: "ignore-leading-blanks"},
+	{Short: 'c', Long: "check"},
+	{Short: 'C'},
+	{Short: 'm', Long: "merge"},
+	{Short: 'o', Long: "output", HasArg: true},
+	{Short: 'z', Long: "zero-terminated"},
+	{Short: 'h', Long: "human-numeric-sort"},
+	{Short: 'V', Long: "version-sort"},
+	{Short: 'g', Long: "general-numeric-sort"},
+	{Short: 's', Long: "stable"},
+	{Short: 'f', Long: "ignore-case"},
+	{Short: 'd', Long: "dictionary-order"},
+	{Short: 'i', Long: "ignore-nonprinting"},
+	{Short: 'T', Long: "temporary-directory", HasArg: true},
+	{Short: 'S', Long: "buffer-size", HasArg: true},
+	{Long: "parallel", HasArg: true},
+	{Long: "locale", HasArg: true},
+	{Long: "files0-from", HasArg: true},
+}
+
+// ParseConfig parses command line arguments into Config
+func ParseConfig(args ...string) (Config, error) {
+	cfg := Config{}
+
+	operands, err := getopt.Parse(args, options, func(opt getopt.Option, value string) error {
+		switch opt.Short {
+		case 'k':
+			key, err := ParseKey(value)
+			if err != nil {
+				return err
+			}
+			cfg.Keys = append(cfg.Keys, key)
+		case 't', 'N':
+			if len(value) != 1 {
+				return fmt.Errorf("multi-character tab '%s'", value)
+			}
+			cfg.Delimiter = value
+		case 'n':
+			cfg.Numeric = true
+		case 'r':
+			cfg.Reverse = true
+		case 'u':
+			cfg.Unique = true
+		case 'M':
+			cfg.Month = true
+		case 'b':
+			cfg.IgnoreBlanks = true
+		case 'c':
+			cfg.CheckSorted = true
+		case 'C':
+			cfg.CheckSorted, cfg.Quiet = true, true
+		case 'm':
+			cfg.Merge = true
+		case 'o':
+			cfg.Output = value
+		case 'z':
+			cfg.ZeroTerminated = true
+		case 'h':
+			cfg.Human = true
+		case 'V':
+			cfg.Version = true
+		case 'g':
+			cfg.General = true
+		case 's':
+			cfg.Stable = true
+		case 'f':
+			cfg.FoldCase = true
+		case 'd':
+			cfg.Dictionary = true
+		case 'i':
+			cfg.IgnoreNonprinting = true
+		case 'T':
+			cfg.TempDir = value
+		case 'S':
+			size, err := parseSize(value)
+			if err != nil {
+				return err
+			}
+			cfg.BufferSize = size
+		case 0:
+			return cfg.setLong(opt.Long, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, err
+	}
+
+	if len(operands) > 0 {
+		cfg.Files = operands
+	}
+	if cfg.Files0From != "" && len(cfg.Files) > 0 {
+		return cfg, fmt.Errorf("extra operand '%s': file operands cannot be combined with --files0-from", cfg.Files[0])
+	}
+	if cfg.CheckSorted {
+		check := "c"
+		if cfg.Quiet {
+			check = "C"
+		}
+		if cfg.Output != "" {
+			return cfg, fmt.Errorf("options '-%so' are incompatible", check)
+		}
+		if len(cfg.Files) > 1 {
+			return cfg, fmt.Errorf("extra operand '%s' not allowed with -%s", cfg.Files[1], check)
+		}
+	}
+	return cfg, nil
+}
+
+// setLong sets an option that only has a long name
+func (cfg *Config) setLong(name, value string) error {
+	switch name {
+	case "parallel":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid --parallel argument '%s'", value)
+		}
+		cfg.Parallel = n
+	case "locale":
+		if _, err := newCollator(value); err != nil {
+			return err
+		}
+		cfg.Locale = value
+	case "files0-from":
+		cfg.Files0From = value
+	}
+	return nil
+}
+
+// parseSize parses the value of -S: a number of kibibytes, or of the unit
+// given by a suffix b, K, M, G or T
+func parseSize(value string) (int64, error) {
+	digits := strings.TrimRight(value, "bkKmMgGtT")
+	unit := value[len(digits):]
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 1 || len(unit) > 1 {
+		return 0, fmt.Errorf("invalid -S argument '%s'", value)
+	}
+
+	shift := map[string]int{"b": 0, "": 10, "k": 10, "m": 20, "g": 30, "t": 40}[strings.ToLower(unit)]
+	if n > math.MaxInt64>>shift {
+		return 0, fmt.Errorf("invalid -S argument '%s'", value)
+	}
+	return n << shift, nil
+}
@@ -0,0 +1,424 @@
+package sort
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// defaultBufferSize is how much input is sorted in memory without -S
+const defaultBufferSize = 64 << 20
+
+// lineOverhead is the memory a line takes to be sorted besides its bytes
+// and the values of its keys after the first
+const lineOverhead = int64(unsafe.Sizeof("") + unsafe.Sizeof(decorated{}))
+
+// keyOverhead is the memory the value of a key takes
+const keyOverhead = int64(unsafe.Sizeof(keyValue{}))
+
+// collatedSize is about how many bytes of collation key a byte of text
+// takes with a locale: two for each of three levels
+const collatedSize = 6
+
+// maxMergeRuns is how many sorted runs are merged at once; more are first
+// merged in groups into longer runs, to bound the open files
+const maxMergeRuns = 64
+
+// SortStream sorts the lines read from the inputs, one after the other,
+// and writes them to w. It gives the output of Sort, but input larger than
+// cfg.BufferSize is sorted in chunks, on several goroutines, which are
+// spilled to temporary files in cfg.TempDir and merged. With cfg.Merge the
+// inputs are taken as sorted and only merged, as they are read.
+func SortStream(ctx context.Context, w io.Writer, cfg Config, inputs ...io.Reader) error {
+	o, err := newOrder(cfg)
+	if err != nil {
+		return err
+	}
+	s := &sorter{order: o, stable: cfg.Stable || cfg.Unique, unique: cfg.Unique, eol: '\n'}
+	if cfg.ZeroTerminated {
+		s.eol = 0
+	}
+
+	lines := newLineReader(inputs, s.eol)
+	if cfg.CheckSorted {
+		return s.checkSorted(lines, checkedName(cfg))
+	}
+
+	out := bufio.NewWriter(w)
+	if cfg.Merge {
+		runs := make([]*lineReader, len(inputs))
+		for i, input := range inputs {
+			runs[i] = newLineReader([]io.Reader{input}, s.eol)
+		}
+		if err := s.mergeRuns(ctx, out, runs); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+
+	bufferSize := cfg.BufferSize
+	if bufferSize == 0 {
+		bufferSize = defaultBufferSize
+	}
+	parallel := cfg.Parallel
+	if parallel == 0 {
+		parallel = runtime.NumCPU()
+	}
+	// the chunks being sorted at once share the buffer
+	chunkSize := bufferSize / int64(parallel)
+
+	chunk, eof, err := s.readChunk(lines, chunkSize)
+	if err != nil {
+		return err
+	}
+	if eof {
+		sorted, err := sortLines(ctx, chunk, s.order, s.stable, s.unique)
+		if err != nil {
+			return err
+		}
+		return s.writeLines(out, sorted)
+	}
+
+	dir, err := os.MkdirTemp(cfg.TempDir, "sort")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	runs, err := s.spillRuns(ctx, dir, lines, chunk, chunkSize, parallel)
+	if err != nil {
+		return err
+	}
+	for len(runs) > maxMergeRuns {
+		merged := filepath.Join(dir, fmt.Sprintf("merged%d", len(runs)))
+		if err := s.mergeToFile(ctx, merged, runs[:maxMergeRuns]); err != nil {
+			return err
+		}
+		runs = append([]string{merged}, runs[maxMergeRuns:]...)
+	}
+	if err := s.mergeFiles(ctx, out, runs); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// sorter is how SortStream orders and writes lines
+type sorter struct {
+	order  *order
+	stable bool // equal lines keep their order
+	unique bool // only the first of equal lines is kept
+	eol    byte // ends every line, a newline or NUL with -z
+}
+
+// spillRuns sorts the first chunk and the rest of the lines in chunks of
+// chunkSize bytes, up to parallel at a time, and writes each one to a file
+// in dir. It returns the files in the order of the input.
+func (s *sorter) spillRuns(ctx context.Context, dir string, lines *lineReader, first []string, chunkSize int64, parallel int) ([]string, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		spillErr error
+		runs     []string
+	)
+	slots := make(chan struct{}, parallel)
+
+	chunk, eof := first, false
+	var err error
+	for len(chunk) > 0 {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		run := filepath.Join(dir, fmt.Sprintf("run%d", len(runs)))
+		runs = append(runs, run)
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := s.writeRun(ctx, run, chunk)
+			if err != nil {
+				mu.Lock()
+				spillErr = errors.Join(spillErr, err)
+				mu.Unlock()
+			}
+		}(chunk)
+
+		if eof {
+			break
+		}
+		// the next chunk is read while the others are sorted
+		chunk, eof, err = s.readChunk(lines, chunkSize)
+		if err != nil {
+			break
+		}
+	}
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	if spillErr != nil {
+		return nil, spillErr
+	}
+	return runs, ctx.Err()
+}
+
+// writeRun sorts a chunk into the file named path
+func (s *sorter) writeRun(ctx context.Context, path string, chunk []string) error {
+	sorted, err := sortLines(ctx, chunk, s.order, s.stable, s.unique)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := s.writeLines(bufio.NewWriter(f), sorted); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// mergeToFile merges sorted runs into the file named path
+func (s *sorter) mergeToFile(ctx context.Context, path string, runs []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := s.mergeFiles(ctx, w, runs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		os.Remove(run)
+	}
+	return nil
+}
+
+// mergeFiles merges the sorted runs in the files at paths into w
+func (s *sorter) mergeFiles(ctx context.Context, w *bufio.Writer, paths []string) error {
+	runs := make([]*lineReader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runs = append(runs, &lineReader{inputs: []io.Reader{f}, eol: s.eol, raw: true})
+	}
+	return s.mergeRuns(ctx, w, runs)
+}
+
+// mergeRuns merges the sorted runs into w. Equal lines come in the order
+// of their runs, which is that of the input, like in the stable sort of
+// one chunk.
+func (s *sorter) mergeRuns(ctx context.Context, w *bufio.Writer, runs []*lineReader) error {
+	h := &runHeap{order: s.order}
+	for i, lines := range runs {
+		r := &runReader{index: i, lines: lines, order: s.order}
+		if ok, err := r.next(); err != nil {
+			return err
+		} else if ok {
+			h.runs = append(h.runs, r)
+		}
+	}
+	heap.Init(h)
+
+	var prev decorated
+	for count := 0; h.Len() > 0; count++ {
+		if count%checkInterval == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		r := h.runs[0]
+		if !s.unique || count == 0 || s.order.compare(&prev, &r.line) != 0 {
+			if err := s.writeLine(w, r.line.line); err != nil {
+				return err
+			}
+		}
+		prev = r.line
+
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// runReader holds the current line of a sorted run, decorated to be
+// compared with the others
+type runReader struct {
+	index int
+	lines *lineReader
+	order *order
+	line  decorated
+}
+
+// next reads the next line of the run and reports whether there was one
+func (r *runReader) next() (bool, error) {
+	line, err := r.lines.next()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	r.line = r.order.decorate(line, make([]keyValue, r.order.restKeys()))
+	return true, nil
+}
+
+// runHeap orders runs by their current line, then by their index
+type runHeap struct {
+	runs  []*runReader
+	order *order
+}
+
+func (h *runHeap) Len() int { return len(h.runs) }
+
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.runs[i], h.runs[j]
+	c := h.order.compare(&a.line, &b.line)
+	return c < 0 || (c == 0 && a.index < b.index)
+}
+
+func (h *runHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *runHeap) Push(x any) { h.runs = append(h.runs, x.(*runReader)) }
+
+func (h *runHeap) Pop() any {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
+
+// checkSorted returns a DisorderError for the first line that comes before
+// the previous one or, with unique, is equal to it. name is the input's.
+func (s *sorter) checkSorted(lines *lineReader, name string) error {
+	var prev decorated
+	for count := 0; ; count++ {
+		line, err := lines.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current := s.order.decorate(line, make([]keyValue, s.order.restKeys()))
+		if count > 0 && outOfOrder(s.order.compare(&prev, &current), s.unique) {
+			return &DisorderError{File: name, Line: count + 1, Text: line}
+		}
+		prev = current
+	}
+}
+
+// readChunk reads lines until they take size bytes of memory to sort, at
+// least one, and reports whether the input ended
+func (s *sorter) readChunk(lines *lineReader, size int64) ([]string, bool, error) {
+	var chunk []string
+	used := int64(0)
+	overhead := lineOverhead + int64(s.order.restKeys())*keyOverhead
+	perByte := int64(1)
+	if s.order.collator != nil {
+		perByte += collatedSize
+	}
+	for len(chunk) == 0 || used < size {
+		line, err := lines.next()
+		if errors.Is(err, io.EOF) {
+			return chunk, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		chunk = append(chunk, line)
+		used += int64(len(line))*perByte + overhead
+	}
+	return chunk, false, nil
+}
+
+// writeLines writes lines, each followed by its end, and flushes w
+func (s *sorter) writeLines(w *bufio.Writer, lines []string) error {
+	for _, line := range lines {
+		if err := s.writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (s *sorter) writeLine(w *bufio.Writer, line string) error {
+	if _, err := w.WriteString(line); err != nil {
+		return err
+	}
+	return w.WriteByte(s.eol)
+}
+
+// lineReader reads the lines of several inputs in turn, which end with
+// eol. Like bufio.ScanLines it drops a newline and a carriage return
+// before it, and a last line may lack its end.
+type lineReader struct {
+	inputs []io.Reader
+	r      *bufio.Reader
+	eol    byte
+	raw    bool // only the end is dropped, as in the runs sort writes
+}
+
+func newLineReader(inputs []io.Reader, eol byte) *lineReader {
+	return &lineReader{inputs: inputs, eol: eol}
+}
+
+// next returns the next line, or io.EOF after the last one
+func (lr *lineReader) next() (string, error) {
+	for {
+		if lr.r == nil {
+			if len(lr.inputs) == 0 {
+				return "", io.EOF
+			}
+			lr.r = bufio.NewReader(lr.inputs[0])
+			lr.inputs = lr.inputs[1:]
+		}
+
+		line, err := lr.r.ReadString(lr.eol)
+		if errors.Is(err, io.EOF) {
+			lr.r = nil
+			if line == "" {
+				continue
+			}
+		} else if err != nil {
+			return "", err
+		}
+
+		line = strings.TrimSuffix(line, string(lr.eol))
+		if lr.raw || lr.eol != '\n' {
+			return line, nil
+		}
+		return strings.TrimSuffix(line, "\r"), nil
+	}
+}
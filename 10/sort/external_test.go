@@ -0,0 +1,238 @@
+package sort_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mysort/sort"
+)
+
+// randomLines returns lines mixing words, numbers with suffixes, months and
+// blanks, with many equal keys
+func randomLines(n int) []string {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"apple", "Banana", "cherry", "Jan", "Feb", "Dec", "x", ""}
+	suffixes := []string{"", "", "K", "M", "x"}
+
+	lines := make([]string, n)
+	for i := range lines {
+		number := fmt.Sprintf("%d%s", rng.Intn(50)-10, suffixes[rng.Intn(len(suffixes))])
+		word := words[rng.Intn(len(words))]
+		switch rng.Intn(4) {
+		case 0:
+			lines[i] = number
+		case 1:
+			lines[i] = word + "\t" + number
+		case 2:
+			lines[i] = strings.Repeat(" ", rng.Intn(3)) + word + " " + number + " " + words[rng.Intn(len(words))]
+		default:
+			lines[i] = word
+		}
+	}
+	return lines
+}
+
+func TestSortStream(t *testing.T) {
+	lines := randomLines(3000)
+	input := strings.Join(lines, "\n") + "\n"
+
+	configs := map[string]sort.Config{
+		"default":       {},
+		"numeric":       {Numeric: true},
+		"reverse":       {Reverse: true},
+		"unique":        {Unique: true},
+		"month":         {Month: true},
+		"ignore blanks": {IgnoreBlanks: true},
+		"human":         {Numeric: true, Human: true},
+		"human alone":   {Human: true, Reverse: true},
+		"keys": {Delimiter: "\t", Keys: []sort.Key{
+			{StartField: 2, EndField: 2, Numeric: true, Reverse: true},
+			{StartField: 1, EndField: 1, FoldCase: true},
+		}},
+		"blank separated key": {Keys: []sort.Key{{StartField: 3}}, Unique: true},
+		"stable":              {Keys: []sort.Key{{StartField: 1, StartChar: 2, EndField: 1, EndChar: 3, StartBlank: true}}, Stable: true},
+		"unique key":          {Keys: []sort.Key{{StartField: 1, EndField: 1, FoldCase: true}}, Unique: true},
+		"stable reverse":      {Keys: []sort.Key{{StartField: 2, EndField: 2}}, Stable: true, Reverse: true},
+		"version":             {Keys: []sort.Key{{StartField: 2, Version: true}}},
+		"general":             {Keys: []sort.Key{{StartField: 1, EndField: 1, General: true, Reverse: true}}},
+		"global version":      {Version: true, Reverse: true},
+		"global general":      {General: true, Unique: true},
+		"locale":              {Locale: "de", FoldCase: true, Unique: true},
+		"dictionary order":    {Dictionary: true, IgnoreNonprinting: true},
+	}
+
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			want, err := sort.Sort(append([]string{}, lines...), cfg)
+			require.NoError(t, err)
+
+			for _, bufferSize := range []int64{0, 512, 4096} {
+				dir := t.TempDir()
+				cfg.TempDir, cfg.BufferSize, cfg.Parallel = dir, bufferSize, 3
+
+				var out bytes.Buffer
+				err := sort.SortStream(context.Background(), &out, cfg, strings.NewReader(input))
+				require.NoError(t, err)
+				assert.Equal(t, strings.Join(want, "\n")+"\n", out.String(), "buffer size %d", bufferSize)
+
+				left, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				assert.Empty(t, left, "temporary files")
+			}
+		})
+	}
+}
+
+func TestSortStreamInputs(t *testing.T) {
+	var out bytes.Buffer
+	inputs := []string{"b\r\nd", "", "a\nc\n"}
+	readers := make([]io.Reader, len(inputs))
+	for i, input := range inputs {
+		readers[i] = strings.NewReader(input)
+	}
+
+	err := sort.SortStream(context.Background(), &out, sort.Config{BufferSize: 1}, readers...)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\nd\n", out.String())
+}
+
+func TestSortStreamZeroTerminated(t *testing.T) {
+	lines := randomLines(2000)
+	for i := range lines {
+		lines[i] += "\n\r"
+	}
+	want, err := sort.Sort(append([]string{}, lines...), sort.Config{})
+	require.NoError(t, err)
+
+	for _, bufferSize := range []int64{0, 512} {
+		cfg := sort.Config{ZeroTerminated: true, TempDir: t.TempDir(), BufferSize: bufferSize}
+		var out bytes.Buffer
+		err := sort.SortStream(context.Background(), &out, cfg, strings.NewReader(strings.Join(lines, "\x00")))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Join(want, "\x00")+"\x00", out.String(), "buffer size %d", bufferSize)
+	}
+}
+
+func TestSortStreamCheck(t *testing.T) {
+	cfg := sort.Config{CheckSorted: true, Numeric: true}
+
+	var out bytes.Buffer
+	err := sort.SortStream(context.Background(), &out, cfg, strings.NewReader("1\n2\n10\n"))
+	assert.NoError(t, err)
+	err = sort.SortStream(context.Background(), &out, cfg, strings.NewReader("1\n10\n2\n"))
+	assert.ErrorIs(t, err, sort.ErrNotSorted)
+	assert.EqualError(t, err, "-:3: disorder: 2")
+	assert.Empty(t, out.String())
+
+	cfg = sort.Config{CheckSorted: true, Unique: true, Files: []string{"words.txt"}}
+	err = sort.SortStream(context.Background(), &out, cfg, strings.NewReader("a\nb\nb\nc\n"))
+	var disorder *sort.DisorderError
+	require.ErrorAs(t, err, &disorder)
+	assert.Equal(t, sort.DisorderError{File: "words.txt", Line: 3, Text: "b"}, *disorder)
+}
+
+func TestSortStreamMerge(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    sort.Config
+		inputs []string
+		want   string
+	}{
+		{
+			name:   "lines",
+			inputs: []string{"a\nc\ne\n", "b\nc\nd\r\n", "", "z\na"},
+			want:   "a\nb\nc\nc\nd\ne\nz\na\n",
+		},
+		{
+			name:   "unique",
+			cfg:    sort.Config{Unique: true},
+			inputs: []string{"a\nc\ne\n", "b\nc\nd\n"},
+			want:   "a\nb\nc\nd\ne\n",
+		},
+		{
+			name:   "equal keys in the order of the inputs",
+			cfg:    sort.Config{Keys: []sort.Key{{StartField: 1, EndField: 1, Numeric: true}}, Stable: true},
+			inputs: []string{"1 x\n2 x\n", "1 y\n2 y\n", "1 z\n"},
+			want:   "1 x\n1 y\n1 z\n2 x\n2 y\n",
+		},
+		{
+			name:   "reverse numbers",
+			cfg:    sort.Config{Numeric: true, Reverse: true},
+			inputs: []string{"100\n9\n", "10\n1\n"},
+			want:   "100\n10\n9\n1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := make([]io.Reader, len(tt.inputs))
+			for i, input := range tt.inputs {
+				readers[i] = strings.NewReader(input)
+			}
+			tt.cfg.Merge = true
+
+			var out bytes.Buffer
+			err := sort.SortStream(context.Background(), &out, tt.cfg, readers...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+// TestSortStreamMergeStreams checks that merged lines are written before
+// the inputs end
+func TestSortStreamMergeStreams(t *testing.T) {
+	r, w := io.Pipe()
+	out := &syncBuffer{}
+	done := make(chan error)
+	go func() {
+		done <- sort.SortStream(context.Background(), out, sort.Config{Merge: true, BufferSize: 1}, r)
+	}()
+
+	_, err := io.WriteString(w, strings.Repeat("line\n", 1<<16))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return out.Len() > 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, w.Close())
+	require.NoError(t, <-done)
+	assert.Equal(t, 5<<16, out.Len())
+}
+
+// syncBuffer is a bytes.Buffer to write and read on different goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func TestSortStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := strings.Join(randomLines(1000), "\n")
+	cfg := sort.Config{TempDir: t.TempDir(), BufferSize: 256}
+	err := sort.SortStream(ctx, &bytes.Buffer{}, cfg, strings.NewReader(input))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,96 @@
+package sort
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// OpenFunc opens a named input; "-" stands for stdin
+type OpenFunc func(name string) (io.ReadCloser, error)
+
+// OpenInputs opens with open the inputs of cfg: the files named in
+// Files0From, else those of Files, else stdin. With Merge an input that is
+// the file of output is spooled to TempDir first, since a merge writes
+// while it reads. Inputs that cannot be opened are reported as
+// "name: reason". closeAll closes every input; on error the inputs opened
+// so far are closed already.
+func OpenInputs(cfg Config, output *Output, open OpenFunc) (inputs []io.Reader, closeAll func(), err error) {
+	var closers []io.Closer
+	closeAll = func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}
+
+	names := cfg.Files
+	if cfg.Files0From != "" {
+		names, err = readFiles0(cfg.Files0From, open)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	inputs = make([]io.Reader, 0, len(names))
+	for _, name := range names {
+		rc, err := openInput(name, open)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, rc)
+
+		var r io.Reader = rc
+		if f, ok := rc.(*os.File); ok && cfg.Merge && output != nil && output.Is(f) {
+			spooled, err := Spool(f, cfg.TempDir)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			closers = append(closers, spooled)
+			r = spooled
+		}
+		inputs = append(inputs, r)
+	}
+	return inputs, closeAll, nil
+}
+
+// openInput opens the input name, which must not be a directory
+func openInput(name string, open OpenFunc) (io.ReadCloser, error) {
+	rc, err := open(name)
+	if err != nil {
+		return nil, fileError(name, err)
+	}
+	if f, ok := rc.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.IsDir() {
+			rc.Close()
+			return nil, fmt.Errorf("%s: is a directory", name)
+		}
+	}
+	return rc, nil
+}
+
+// readFiles0 reads the input names of --files0-from from the input from
+func readFiles0(from string, open OpenFunc) ([]string, error) {
+	rc, err := openInput(from, open)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ReadFiles0(rc, from)
+}
+
+// fileError reports err of the input name without the operation and path
+// of an fs.PathError, like "a.txt: no such file or directory"
+func fileError(name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
@@ -0,0 +1,346 @@
+package sort
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mysort/internal/collate"
+)
+
+// Key is a sort key of -k POS1[,POS2], where a position is F[.C][OPTS],
+// as in GNU sort. Without a delimiter a field is a run of non-blanks with
+// the blanks before it.
+type Key struct {
+	StartField int  // field the key starts in, from 1
+	StartChar  int  // character of the field the key starts at, from 1
+	EndField   int  // field the key ends in, 0 for the end of the line
+	EndChar    int  // last character of the key in its field, 0 for the end of the field
+	StartBlank bool // b on POS1: blanks are skipped before StartChar is counted
+	EndBlank   bool // b on POS2: blanks are skipped before EndChar is counted
+
+	Numeric           bool // n
+	Human             bool // h
+	General           bool // g
+	Month             bool // M
+	Version           bool // V
+	Dictionary        bool // d, only blanks and letters and digits count
+	FoldCase          bool // f, lowercase letters count as uppercase
+	IgnoreNonprinting bool // i, only printable characters count
+	Reverse           bool // r
+}
+
+// ParseKey parses the value of -k
+func ParseKey(spec string) (Key, error) {
+	invalid := fmt.Errorf("invalid field specification '%s'", spec)
+	key := Key{}
+
+	start, end, hasEnd := strings.Cut(spec, ",")
+	field, char, opts, err := parsePosition(start)
+	if err != nil || field == 0 || (char == 0 && strings.Contains(start, ".")) {
+		return key, invalid
+	}
+	key.StartField, key.StartChar = field, char
+	if !key.setOptions(opts, &key.StartBlank) {
+		return key, invalid
+	}
+
+	if hasEnd {
+		field, char, opts, err := parsePosition(end)
+		if err != nil || field == 0 {
+			return key, invalid
+		}
+		key.EndField, key.EndChar = field, char
+		if !key.setOptions(opts, &key.EndBlank) {
+			return key, invalid
+		}
+	}
+	return key, nil
+}
+
+// parsePosition splits F[.C][OPTS] into its parts; C is 0 when missing
+func parsePosition(pos string) (field, char int, opts string, err error) {
+	digits := len(pos) - len(strings.TrimLeft(pos, "0123456789"))
+	field, err = strconv.Atoi(pos[:digits])
+	if err != nil {
+		return 0, 0, "", err
+	}
+	pos = pos[digits:]
+
+	if rest, ok := strings.CutPrefix(pos, "."); ok {
+		digits = len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		char, err = strconv.Atoi(rest[:digits])
+		if err != nil {
+			return 0, 0, "", err
+		}
+		pos = rest[digits:]
+	}
+	return field, char, pos, nil
+}
+
+// setOptions sets the ordering options given by letters; b sets blank. It
+// reports whether all the letters are options.
+func (k *Key) setOptions(letters string, blank *bool) bool {
+	for _, c := range letters {
+		switch c {
+		case 'b':
+			*blank = true
+		case 'n':
+			k.Numeric = true
+		case 'h':
+			k.Human = true
+		case 'g':
+			k.General = true
+		case 'M':
+			k.Month = true
+		case 'V':
+			k.Version = true
+		case 'd':
+			k.Dictionary = true
+		case 'f':
+			k.FoldCase = true
+		case 'i':
+			k.IgnoreNonprinting = true
+		case 'r':
+			k.Reverse = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasOptions reports whether the key has options of its own; a key without
+// any takes the global ones
+func (k Key) hasOptions() bool {
+	return k.StartBlank || k.EndBlank || k.Numeric || k.Human || k.General || k.Month ||
+		k.Version || k.Dictionary || k.FoldCase || k.IgnoreNonprinting || k.Reverse
+}
+
+// globalKey is the whole line ordered by the global options of cfg
+func globalKey(cfg Config) Key {
+	return Key{
+		StartBlank: cfg.IgnoreBlanks,
+		EndBlank:   cfg.IgnoreBlanks,
+		Numeric:    cfg.Numeric,
+		Human:      cfg.Human,
+		General:    cfg.General,
+		Month:      cfg.Month,
+		Version:    cfg.Version,
+		Reverse:    cfg.Reverse,
+
+		Dictionary:        cfg.Dictionary,
+		FoldCase:          cfg.FoldCase,
+		IgnoreNonprinting: cfg.IgnoreNonprinting,
+	}
+}
+
+// withOptions returns the key at the position of k with the options of o
+func (k Key) withOptions(o Key) Key {
+	o.StartField, o.StartChar, o.EndField, o.EndChar = k.StartField, k.StartChar, k.EndField, k.EndChar
+	return o
+}
+
+// extract returns the part of line the key covers
+func (k Key) extract(line, delimiter string) string {
+	start := k.begin(line, delimiter)
+	end := len(line)
+	if k.EndField > 0 {
+		end = max(k.limit(line, delimiter), start)
+	}
+	return line[start:end]
+}
+
+// begin returns where the key starts in line
+func (k Key) begin(line, delimiter string) int {
+	pos := 0
+	for field := 1; field < k.StartField && pos < len(line); field++ {
+		pos = skipField(line, pos, delimiter)
+		if delimiter != "" && pos < len(line) {
+			pos++
+		}
+	}
+	if k.StartBlank {
+		pos = skipBlanks(line, pos)
+	}
+	return min(len(line), pos+max(k.StartChar-1, 0))
+}
+
+// limit returns where the key ends in line; without EndChar the key takes
+// all of its last field
+func (k Key) limit(line, delimiter string) int {
+	fields := k.EndField - 1
+	if k.EndChar == 0 {
+		fields++
+	}
+
+	pos := 0
+	for ; fields > 0 && pos < len(line); fields-- {
+		pos = skipField(line, pos, delimiter)
+		// the delimiter ending the last field is not part of the key
+		if delimiter != "" && pos < len(line) && (fields > 1 || k.EndChar != 0) {
+			pos++
+		}
+	}
+	if k.EndChar != 0 {
+		if k.EndBlank {
+			pos = skipBlanks(line, pos)
+		}
+		pos = min(len(line), pos+k.EndChar)
+	}
+	return pos
+}
+
+// skipField returns the end of the field at pos: the next delimiter or,
+// without one, the end of the blanks and non-blanks at pos
+func skipField(line string, pos int, delimiter string) int {
+	if delimiter != "" {
+		if i := strings.IndexByte(line[pos:], delimiter[0]); i >= 0 {
+			return pos + i
+		}
+		return len(line)
+	}
+	pos = skipBlanks(line, pos)
+	for pos < len(line) && !isBlank(line[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func skipBlanks(line string, pos int) int {
+	for pos < len(line) && isBlank(line[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// keyValue is the key of a line as it is compared, computed once per line
+type keyValue struct {
+	text     string  // the key; with -d, -f and -i what counts of it
+	collated string  // the collation key of text with a locale
+	number   number  // -n and -h
+	float    float64 // -g, when parsed
+	parsed   bool
+	month    int // -M
+	prefix   int // -V: the length of text without its suffix
+}
+
+// value returns the key of line as it is compared; text is collated by
+// collator, or compared by its bytes when it is nil
+func (k Key) value(line, delimiter string, collator *collate.Collator) keyValue {
+	text := k.extract(line, delimiter)
+	switch {
+	case k.Numeric || k.Human:
+		return keyValue{number: parseNumber(text, k.Human)}
+	case k.General:
+		v, ok := parseGeneral(text)
+		return keyValue{float: v, parsed: ok}
+	case k.Month:
+		return keyValue{month: parseMonth(text)}
+	case k.Version:
+		return keyValue{text: text, prefix: versionPrefixLen(text)}
+	case k.Dictionary || k.FoldCase || k.IgnoreNonprinting:
+		text = k.filter(text, collator != nil)
+	}
+	if collator == nil {
+		return keyValue{text: text}
+	}
+	return keyValue{text: text, collated: string(collator.Key(text))}
+}
+
+// compare compares the values of the key in two lines
+func (k Key) compare(a, b *keyValue) int {
+	var c int
+	switch {
+	case k.Numeric || k.Human:
+		c = compareNumbers(a.number, b.number)
+	case k.General:
+		c = compareGeneral(a.float, a.parsed, b.float, b.parsed)
+	case k.Month:
+		c = cmp.Compare(a.month, b.month)
+	case k.Version:
+		c = compareVersions(a.text, a.prefix, b.text, b.prefix)
+	default:
+		c = compareText(a.text, a.collated, b.text, b.collated)
+	}
+
+	if k.Reverse {
+		return -c
+	}
+	return c
+}
+
+// compareText compares text by its collation keys, which are empty
+// without a locale, then by its bytes
+func compareText(a, aCollated, b, bCollated string) int {
+	if c := strings.Compare(aCollated, bCollated); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// filter drops what does not count in the key with -d and -i and with -f
+// folds the rest to uppercase: bytes, as in the C locale, or with unicode
+// characters, which count by their Unicode properties
+func (k Key) filter(s string, unicode bool) string {
+	if unicode {
+		return strings.Map(k.filterRune, s)
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if k.ignored(c) {
+			continue
+		}
+		if k.FoldCase {
+			c = toUpper(c)
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+func (k Key) filterRune(r rune) rune {
+	if k.Dictionary && r != ' ' && r != '\t' && !unicode.IsLetter(r) && !unicode.IsDigit(r) ||
+		k.IgnoreNonprinting && !unicode.IsPrint(r) {
+		return -1
+	}
+	if k.FoldCase {
+		return unicode.ToUpper(r)
+	}
+	return r
+}
+
+// ignored reports whether c does not count in the key
+func (k Key) ignored(c byte) bool {
+	if k.Dictionary && !isBlank(c) && !isAlnum(c) {
+		return true
+	}
+	return k.IgnoreNonprinting && (c < ' ' || c > '~')
+}
+
+func isAlnum(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func toUpper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
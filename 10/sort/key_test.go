@@ -0,0 +1,334 @@
+package sort_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mysort/sort"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    sort.Key
+		wantErr bool
+	}{
+		{spec: "2", want: sort.Key{StartField: 2}},
+		{spec: "2,2n", want: sort.Key{StartField: 2, EndField: 2, Numeric: true}},
+		{spec: "1,1r", want: sort.Key{StartField: 1, EndField: 1, Reverse: true}},
+		{spec: "2.3,2.5", want: sort.Key{StartField: 2, StartChar: 3, EndField: 2, EndChar: 5}},
+		{spec: "1.2b,3bf", want: sort.Key{StartField: 1, StartChar: 2, StartBlank: true, EndField: 3, EndBlank: true, FoldCase: true}},
+		{spec: "3,3.0", want: sort.Key{StartField: 3, EndField: 3}},
+		{spec: "1dgihMVr", want: sort.Key{StartField: 1, Dictionary: true, General: true, IgnoreNonprinting: true, Human: true, Month: true, Version: true, Reverse: true}},
+		{spec: "0", wantErr: true},
+		{spec: "1.0", wantErr: true},
+		{spec: "1,0", wantErr: true},
+		{spec: "x", wantErr: true},
+		{spec: "1,", wantErr: true},
+		{spec: "2q", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			key, err := sort.ParseKey(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
+
+// hasGNUSort reports whether the system has GNU sort to compare with
+func hasGNUSort() bool {
+	out, err := exec.Command("sort", "--version").Output()
+	return err == nil && strings.Contains(string(out), "GNU coreutils")
+}
+
+// TestSortKeys sorts with keys given as on the command line and, when it
+// is installed, checks the expected output with GNU sort in the C locale
+func TestSortKeys(t *testing.T) {
+	report := []string{
+		"team-b 120 api",
+		"team-a 80 db",
+		"team-b 300 web",
+		"team-a 80 api",
+		"team-a 95 cache",
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "team then latency descending",
+			args:  []string{"-k", "1,1", "-k", "2,2nr"},
+			lines: report,
+			want:  []string{"team-a 95 cache", "team-a 80 api", "team-a 80 db", "team-b 300 web", "team-b 120 api"},
+		},
+		{
+			name:  "stable keeps the input order of equal keys",
+			args:  []string{"-s", "-k", "1,1"},
+			lines: report,
+			want:  []string{"team-a 80 db", "team-a 80 api", "team-a 95 cache", "team-b 120 api", "team-b 300 web"},
+		},
+		{
+			name:  "last resort orders lines with equal keys",
+			args:  []string{"-k", "1,1"},
+			lines: report,
+			want:  []string{"team-a 80 api", "team-a 80 db", "team-a 95 cache", "team-b 120 api", "team-b 300 web"},
+		},
+		{
+			name:  "stable reverse",
+			args:  []string{"-s", "-r", "-k", "1,1"},
+			lines: report,
+			want:  []string{"team-b 120 api", "team-b 300 web", "team-a 80 db", "team-a 80 api", "team-a 95 cache"},
+		},
+		{
+			name:  "unique keeps the first line of each key",
+			args:  []string{"-u", "-k", "1,1"},
+			lines: report,
+			want:  []string{"team-a 80 db", "team-b 120 api"},
+		},
+		{
+			name:  "unique by reversed numeric key",
+			args:  []string{"-u", "-k", "2,2nr"},
+			lines: report,
+			want:  []string{"team-b 300 web", "team-b 120 api", "team-a 95 cache", "team-a 80 db"},
+		},
+		{
+			name:  "unique by numeric value",
+			args:  []string{"-un"},
+			lines: []string{"10", "9.0", "010", "9"},
+			want:  []string{"9.0", "10"},
+		},
+		{
+			name:  "unique whole lines",
+			args:  []string{"-u"},
+			lines: []string{"b", "a", "b", "B"},
+			want:  []string{"B", "a", "b"},
+		},
+		{
+			name:  "key to the end of the line",
+			args:  []string{"-k", "2"},
+			lines: []string{"x 1 b", "y 1 a", "z 0 c"},
+			want:  []string{"z 0 c", "y 1 a", "x 1 b"},
+		},
+		{
+			name:  "character offsets",
+			args:  []string{"-k", "2.4,2.5"},
+			lines: []string{"a xxzyq", "b xxayz", "c xxzaa"},
+			want:  []string{"b xxayz", "c xxzaa", "a xxzyq"},
+		},
+		{
+			name:  "blanks count in offsets without b",
+			args:  []string{"-k", "2.2,2.2"},
+			lines: []string{"a  yx", "b xz", "c  aw"},
+			want:  []string{"a  yx", "c  aw", "b xz"},
+		},
+		{
+			name:  "blanks skipped with b",
+			args:  []string{"-k", "2.2b,2.2b"},
+			lines: []string{"a  yx", "b xz", "c  aw"},
+			want:  []string{"c  aw", "a  yx", "b xz"},
+		},
+		{
+			name:  "delimiter",
+			args:  []string{"-t", ":", "-k", "3,3n", "-k", "1,1r"},
+			lines: []string{"a:x:10", "b:y:9", "c:z:10", "d::9"},
+			want:  []string{"d::9", "b:y:9", "c:z:10", "a:x:10"},
+		},
+		{
+			name:  "empty fields",
+			args:  []string{"-t", ",", "-k", "2,2"},
+			lines: []string{"a,,c", "b,a,", "c"},
+			want:  []string{"a,,c", "c", "b,a,"},
+		},
+		{
+			name:  "global options for keys without their own",
+			args:  []string{"-r", "-k", "2,2", "-k", "1,1n"},
+			lines: []string{"1 a", "2 b", "10 b", "3 a"},
+			want:  []string{"2 b", "10 b", "1 a", "3 a"},
+		},
+		{
+			name:  "last resort follows the global reverse",
+			args:  []string{"-r", "-k", "2,2n"},
+			lines: []string{"a 1", "b 2", "c 1"},
+			want:  []string{"c 1", "a 1", "b 2"},
+		},
+		{
+			name:  "fold case",
+			args:  []string{"-k", "1,1f"},
+			lines: []string{"b", "A", "a", "B", "_c"},
+			want:  []string{"A", "a", "B", "b", "_c"},
+		},
+		{
+			name:  "dictionary order",
+			args:  []string{"-k", "1,1d"},
+			lines: []string{"b-2", "a_3", "#a1", "b1"},
+			want:  []string{"#a1", "a_3", "b1", "b-2"},
+		},
+		{
+			name:  "ignore nonprinting",
+			args:  []string{"-k", "1,1i"},
+			lines: []string{"b", "\x01c", "a\x7f"},
+			want:  []string{"a\x7f", "b", "\x01c"},
+		},
+		{
+			name:  "global fold case",
+			args:  []string{"-f"},
+			lines: []string{"b", "A", "a", "B", "_c"},
+			want:  []string{"A", "a", "B", "b", "_c"},
+		},
+		{
+			name:  "global fold case unique",
+			args:  []string{"-fu"},
+			lines: []string{"b", "a", "B", "A"},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "global dictionary order",
+			args:  []string{"-d"},
+			lines: []string{"b-2", "a_3", "#a1", "b1"},
+			want:  []string{"#a1", "a_3", "b1", "b-2"},
+		},
+		{
+			name:  "global ignore nonprinting",
+			args:  []string{"-i"},
+			lines: []string{"b", "\x01c", "a\x7f"},
+			want:  []string{"a\x7f", "b", "\x01c"},
+		},
+		{
+			name:  "global dictionary order and fold case",
+			args:  []string{"-df"},
+			lines: []string{"B.2", "a-3", "b1", "A:1"},
+			want:  []string{"A:1", "a-3", "b1", "B.2"},
+		},
+		{
+			name:  "global options for a key without its own",
+			args:  []string{"-f", "-k", "2,2"},
+			lines: []string{"1 b", "2 B", "3 a"},
+			want:  []string{"3 a", "1 b", "2 B"},
+		},
+		{
+			name:  "version",
+			args:  []string{"-k", "1,1V"},
+			lines: []string{"app-1.10.2", "app-1.9.0", "app-1.9.0~rc1", "app-1.9", "app-1.09.1"},
+			want:  []string{"app-1.9", "app-1.9.0~rc1", "app-1.9.0", "app-1.09.1", "app-1.10.2"},
+		},
+		{
+			name:  "human numbers by unit first",
+			args:  []string{"-h"},
+			lines: []string{"1Y", "1Z", "1E", "1P", "1T", "1G", "1M", "1k", "1K", "2", "1Ki", "1023Ki", "999", "0K", "-1K", "-2", "abc"},
+			want:  []string{"-1K", "-2", "0K", "abc", "2", "999", "1K", "1Ki", "1k", "1023Ki", "1M", "1G", "1T", "1P", "1E", "1Z", "1Y"},
+		},
+		{
+			name:  "human numbers with fractions and unknown suffixes",
+			args:  []string{"-h", "-k", "2,2"},
+			lines: []string{"a 1.5G", "b 1.25G", "c 2000M", "d 3R", "e 0.0M", "f 1.G"},
+			want:  []string{"e 0.0M", "d 3R", "c 2000M", "f 1.G", "b 1.25G", "a 1.5G"},
+		},
+		{
+			name:  "human numbers reversed",
+			args:  []string{"-hr"},
+			lines: []string{"10K", "2M", "500", "1.5M"},
+			want:  []string{"2M", "1.5M", "10K", "500"},
+		},
+		{
+			name:  "numbers are read at the start of keys",
+			args:  []string{"-n"},
+			lines: []string{"+5", "12abc", "abc", "", "-0", "0", ".5", "-.5", "1.", "007", "1e3", " 3", "-abc", "1,000"},
+			want:  []string{"-.5", "", "+5", "-0", "-abc", "0", "abc", ".5", "1,000", "1.", "1e3", " 3", "007", "12abc"},
+		},
+		{
+			name:  "long numbers",
+			args:  []string{"-n"},
+			lines: []string{"123456789012345678901234567890", "123456789012345678901234567891", "-99999999999999999999.5", "-99999999999999999999.25", "0.000000000000000000001"},
+			want:  []string{"-99999999999999999999.5", "-99999999999999999999.25", "0.000000000000000000001", "123456789012345678901234567890", "123456789012345678901234567891"},
+		},
+		{
+			name:  "unique numbers",
+			args:  []string{"-nu"},
+			lines: []string{"abc", "0", "-0.0", "2.50", "2.5", "x"},
+			want:  []string{"abc", "2.50"},
+		},
+		{
+			name:  "months in any case, with blanks and full names",
+			args:  []string{"-M"},
+			lines: []string{"january", " DEC", "feb", "Janx", "may", "xyz", "", "Ma", "SEPTEMBER"},
+			want:  []string{"", "Ma", "xyz", "Janx", "january", "feb", "may", "SEPTEMBER", " DEC"},
+		},
+		{
+			name:  "month key",
+			args:  []string{"-k", "2,2M", "-k", "1,1n"},
+			lines: []string{"3 Mar", "1 mar", "2 foo", "1 Jan"},
+			want:  []string{"2 foo", "1 Jan", "1 mar", "3 Mar"},
+		},
+		{
+			name:  "global version",
+			args:  []string{"-V"},
+			lines: []string{"app-1.10.2", "app-1.9.0", "app-1.9.0-rc1", "app-1.9.0-rc10", "app-1.9.0-rc2", "app-1.9.0~rc1", "app-1.9", "app-1.9.0.tar.gz", "app-1.9.0a", "App-2"},
+			want:  []string{"App-2", "app-1.9", "app-1.9.0~rc1", "app-1.9.0", "app-1.9.0.tar.gz", "app-1.9.0a", "app-1.9.0-rc1", "app-1.9.0-rc2", "app-1.9.0-rc10", "app-1.10.2"},
+		},
+		{
+			name:  "global version for a key without its own",
+			args:  []string{"-V", "-r", "-k", "2,2"},
+			lines: []string{"a 1.9", "b 1.10", "c 1.9"},
+			want:  []string{"b 1.10", "c 1.9", "a 1.9"},
+		},
+		{
+			name:  "global general numbers",
+			args:  []string{"-g"},
+			lines: []string{"1e3", "0x1p4", "NaN", "-inf", "1.5E-2", "+3", "abc", "-0x10", "2.5e+2", "infinity", "0.1e1", ""},
+			want:  []string{"", "abc", "NaN", "-inf", "-0x10", "1.5E-2", "0.1e1", "+3", "0x1p4", "2.5e+2", "1e3", "infinity"},
+		},
+		{
+			name:  "global general numbers reversed",
+			args:  []string{"-gr"},
+			lines: []string{"1e3", "0x1p4", "NaN", "-inf", "1.5E-2", "+3", "abc", "-0x10"},
+			want:  []string{"1e3", "0x1p4", "+3", "1.5E-2", "-0x10", "-inf", "NaN", "abc"},
+		},
+		{
+			name:  "global general numbers unique",
+			args:  []string{"-gu"},
+			lines: []string{"100", "1e2", "0x64", "1.0e+2", "nan", "x", "y"},
+			want:  []string{"x", "nan", "100"},
+		},
+		{
+			name:  "general numbers",
+			args:  []string{"-k", "1,1g"},
+			lines: []string{"1e3", "0x10", "-inf", "abc", "2.5", "inf", "-1.5e-3"},
+			want:  []string{"abc", "-inf", "-1.5e-3", "2.5", "0x10", "1e3", "inf"},
+		},
+	}
+
+	gnu := hasGNUSort()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := sort.ParseConfig(tt.args...)
+			require.NoError(t, err)
+			got, err := sort.Sort(append([]string{}, tt.lines...), cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			if !gnu {
+				return
+			}
+			cmd := exec.Command("sort", tt.args...)
+			cmd.Env = append(os.Environ(), "LC_ALL=C")
+			cmd.Stdin = strings.NewReader(strings.Join(tt.lines, "\n") + "\n")
+			out, err := cmd.Output()
+			require.NoError(t, err)
+			assert.Equal(t, strings.Join(tt.want, "\n")+"\n", string(out), "GNU output")
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package sort
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Output is the file of -o. It is created, or truncated, at the first
+// write or else at Finish, so the file can also be an input: SortStream
+// reads all of its input before it writes, except with Merge, for which
+// such an input is read through Spool.
+type Output struct {
+	name string
+	f    *os.File
+}
+
+// NewOutput returns the Output of the file at name
+func NewOutput(name string) *Output {
+	return &Output{name: name}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	if err := o.open(); err != nil {
+		return 0, err
+	}
+	return o.f.Write(p)
+}
+
+// Finish closes the file after the sort that ended with err, and returns
+// err or else the error of closing. A sort that wrote nothing creates the
+// file empty, unless it failed: then the file is left alone.
+func (o *Output) Finish(err error) error {
+	if o.f == nil {
+		if err != nil {
+			return err
+		}
+		if err := o.open(); err != nil {
+			return err
+		}
+	}
+	if closeErr := o.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (o *Output) open() error {
+	if o.f != nil {
+		return nil
+	}
+	f, err := os.Create(o.name)
+	if err != nil {
+		return err
+	}
+	o.f = f
+	return nil
+}
+
+// Is reports whether the input f is the file of o
+func (o *Output) Is(f *os.File) bool {
+	out, err := os.Stat(o.name)
+	if err != nil {
+		return false
+	}
+	in, err := f.Stat()
+	return err == nil && os.SameFile(in, out)
+}
+
+// Spool copies r to a temporary file in dir, the system's when empty, and
+// returns the file at its start. The file is removed at once and goes
+// away when it is closed.
+func Spool(r io.Reader, dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "sort")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadFiles0 reads the names of --files0-from, which end with NUL, from r.
+// from is the name of r, "-" for stdin, which then cannot be a name.
+func ReadFiles0(r io.Reader, from string) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no input from '%s'", from)
+	}
+
+	names := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	for i, name := range names {
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%s:%d: invalid zero-length file name", from, i+1)
+		case name == "-" && from == "-":
+			return nil, errors.New("when reading file names from stdin, no file name of '-' allowed")
+		}
+	}
+	return names, nil
+}
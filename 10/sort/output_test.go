@@ -0,0 +1,183 @@
+package sort_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mysort/sort"
+)
+
+func TestOutputIsInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lines.txt")
+	require.NoError(t, os.WriteFile(path, []byte("c\na\nb\n"), 0o644))
+
+	in, err := os.Open(path)
+	require.NoError(t, err)
+	defer in.Close()
+
+	output := sort.NewOutput(path)
+	assert.True(t, output.Is(in))
+	err = output.Finish(sort.SortStream(context.Background(), output, sort.Config{}, in))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\n", string(data))
+}
+
+func TestOutputMergeSpooled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "merged.txt")
+	require.NoError(t, os.WriteFile(path, []byte("a\nc\n"), 0o644))
+
+	in, err := os.Open(path)
+	require.NoError(t, err)
+	defer in.Close()
+	spooled, err := sort.Spool(in, dir)
+	require.NoError(t, err)
+	defer spooled.Close()
+
+	left, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, left, 1, "the spooled file is removed")
+
+	output := sort.NewOutput(path)
+	cfg := sort.Config{Merge: true}
+	err = output.Finish(sort.SortStream(context.Background(), output, cfg, spooled, strings.NewReader("b\nd\n")))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\nd\n", string(data))
+}
+
+func TestOutputFinish(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.txt")
+	require.NoError(t, sort.NewOutput(empty).Finish(nil))
+	assert.FileExists(t, empty)
+
+	failed := filepath.Join(dir, "failed.txt")
+	require.NoError(t, os.WriteFile(failed, []byte("kept\n"), 0o644))
+	errRead := errors.New("read failed")
+	assert.ErrorIs(t, sort.NewOutput(failed).Finish(errRead), errRead)
+	data, err := os.ReadFile(failed)
+	require.NoError(t, err)
+	assert.Equal(t, "kept\n", string(data))
+
+	missing := filepath.Join(dir, "no", "such.txt")
+	assert.Error(t, sort.NewOutput(missing).Finish(nil))
+	_, err = sort.NewOutput(missing).Write([]byte("a\n"))
+	assert.Error(t, err)
+}
+
+func TestReadFiles0(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		from    string
+		want    []string
+		wantErr string
+	}{
+		{name: "names", input: "a.txt\x00b c.txt\x00", from: "list", want: []string{"a.txt", "b c.txt"}},
+		{name: "last name without NUL", input: "a.txt\x00-", from: "list", want: []string{"a.txt", "-"}},
+		{name: "empty", input: "", from: "list", wantErr: "no input from 'list'"},
+		{name: "empty name", input: "a.txt\x00\x00b.txt", from: "list", wantErr: "list:2: invalid zero-length file name"},
+		{name: "stdin in stdin", input: "a.txt\x00-\x00", from: "-", wantErr: "when reading file names from stdin, no file name of '-' allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := sort.ReadFiles0(strings.NewReader(tt.input), tt.from)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestSpoolError(t *testing.T) {
+	_, err := sort.Spool(io.MultiReader(strings.NewReader("a"), errReader{}), t.TempDir())
+	assert.Error(t, err)
+}
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestOpenInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     sort.Config
+		output  string
+		want    []string
+		wantErr string
+	}{
+		{name: "stdin", want: []string{"from stdin\n"}},
+		{name: "files", cfg: sort.Config{Files: []string{"a.txt", "-", "b.txt"}}, want: []string{"a\n", "from stdin\n", "b\n"}},
+		{name: "files0 from", cfg: sort.Config{Files0From: "list"}, want: []string{"b\n", "a\n"}},
+		{name: "missing file", cfg: sort.Config{Files: []string{"a.txt", "nosuch.txt"}}, wantErr: "nosuch.txt: no such file or directory"},
+		{name: "missing list", cfg: sort.Config{Files0From: "nosuch"}, wantErr: "nosuch: no such file or directory"},
+		{name: "directory", cfg: sort.Config{Files: []string{"dir"}}, wantErr: "dir: is a directory"},
+		{name: "output not merged", cfg: sort.Config{Files: []string{"a.txt"}}, output: "a.txt", want: []string{"a\n"}},
+		{name: "output merged", cfg: sort.Config{Files: []string{"a.txt"}, Merge: true}, output: "a.txt", want: []string{"a\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "list"), []byte("b.txt\x00a.txt\x00"), 0o644))
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "dir"), 0o755))
+
+			open := func(name string) (io.ReadCloser, error) {
+				if name == "-" {
+					return io.NopCloser(strings.NewReader("from stdin\n")), nil
+				}
+				return os.Open(filepath.Join(dir, name))
+			}
+			var output *sort.Output
+			if tt.output != "" {
+				output = sort.NewOutput(filepath.Join(dir, tt.output))
+			}
+
+			inputs, closeInputs, err := sort.OpenInputs(tt.cfg, output, open)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer closeInputs()
+
+			var got []string
+			for _, r := range inputs {
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				got = append(got, string(data))
+			}
+			assert.Equal(t, tt.want, got)
+			if tt.output != "" {
+				f, ok := inputs[0].(*os.File)
+				require.True(t, ok)
+				spooled := f.Name() != filepath.Join(dir, tt.output)
+				assert.Equal(t, tt.cfg.Merge, spooled)
+			}
+		})
+	}
+}
@@ -0,0 +1,470 @@
+package sort
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mysort/internal/collate"
+)
+
+// ErrNotSorted is returned when input is not sorted.
+var ErrNotSorted = errors.New("input is not sorted")
+
+// DisorderError is the first line -c finds out of order. It is
+// ErrNotSorted.
+type DisorderError struct {
+	File string // name of the input, "-" for stdin
+	Line int    // number of the line, from 1
+	Text string // the line
+}
+
+func (e *DisorderError) Error() string {
+	return fmt.Sprintf("%s:%d: disorder: %s", e.File, e.Line, e.Text)
+}
+
+func (e *DisorderError) Unwrap() error {
+	return ErrNotSorted
+}
+
+// checkedName is the name of the input -c checks, which is the only one
+func checkedName(cfg Config) string {
+	if len(cfg.Files) == 0 {
+		return "-"
+	}
+	return cfg.Files[0]
+}
+
+// checkInterval is how many comparisons are made between context checks
+const checkInterval = 4096
+
+// Sort sorts lines according to cfg and returns a new slice.
+func Sort(lines []string, cfg Config) ([]string, error) {
+	return SortContext(context.Background(), lines, cfg)
+}
+
+// SortContext is like Sort but stops with ctx.Err() once ctx is done.
+func SortContext(ctx context.Context, lines []string, cfg Config) ([]string, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	// copy lines to not modify original
+	out := make([]string, len(lines))
+	copy(out, lines)
+
+	o, err := newOrder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CheckSorted {
+		lines := o.decorateAll(out)
+		for i := 0; i < len(lines)-1; i++ {
+			if outOfOrder(o.compare(lines[i], lines[i+1]), cfg.Unique) {
+				return nil, &DisorderError{File: checkedName(cfg), Line: i + 2, Text: out[i+1]}
+			}
+		}
+		return nil, nil
+	}
+
+	if cfg.Merge {
+		// the lines are one sorted run, as one input is to SortStream
+		if cfg.Unique {
+			lines := slices.CompactFunc(o.decorateAll(out), func(a, b *decorated) bool {
+				return o.compare(a, b) == 0
+			})
+			out = undecorate(out, lines)
+		}
+		return out, nil
+	}
+
+	return sortLines(ctx, out, o, cfg.Stable || cfg.Unique, cfg.Unique)
+}
+
+// sortLines sorts lines in place and with unique keeps only the first of
+// the lines that compare equal. A stable sort keeps equal lines in their
+// order, which unique needs to keep the first one of the input. The keys
+// of each line are computed once, before the lines are sorted by them.
+func sortLines(ctx context.Context, lines []string, o *order, stable, unique bool) ([]string, error) {
+	if len(o.keys) == 0 && o.collator == nil {
+		// the lines are their own keys
+		return sortSlice(ctx, lines, o.compareLines, stable, unique)
+	}
+	sorted, err := sortSlice(ctx, o.decorateAll(lines), o.compare, stable, unique)
+	if err != nil {
+		return nil, err
+	}
+	return undecorate(lines, sorted), nil
+}
+
+// sortSlice sorts s in place by compare like sortLines sorts lines
+func sortSlice[E any](ctx context.Context, s []E, compare func(a, b E) int, stable, unique bool) ([]E, error) {
+	// a canceled sort keeps calling compare, which then answers immediately
+	calls, canceled := 0, false
+	checked := func(a, b E) int {
+		if canceled {
+			return 0
+		}
+		calls++
+		if calls%checkInterval == 0 && ctx.Err() != nil {
+			canceled = true
+			return 0
+		}
+		return compare(a, b)
+	}
+	if stable {
+		slices.SortStableFunc(s, checked)
+	} else {
+		slices.SortFunc(s, checked)
+	}
+	if canceled {
+		return nil, ctx.Err()
+	}
+
+	if unique {
+		s = slices.CompactFunc(s, func(a, b E) bool {
+			return compare(a, b) == 0
+		})
+	}
+	return s, nil
+}
+
+// order is the order of lines given by a Config: by the keys in turn, then
+// by the whole line as a last resort
+type order struct {
+	keys       []Key
+	delimiter  string
+	collator   *collate.Collator
+	lastResort bool
+	reverse    bool
+}
+
+// decorated is a line with the values of its keys. The first is kept in
+// it, so comparing by one key reads only the lines being sorted.
+type decorated struct {
+	line     string
+	collated string // the collation key of the line for the last resort
+	first    keyValue
+	rest     []keyValue
+}
+
+// key returns the value of the i-th key
+func (d *decorated) key(i int) *keyValue {
+	if i == 0 {
+		return &d.first
+	}
+	return &d.rest[i-1]
+}
+
+// newOrder returns the order of lines given by cfg: by the keys in turn,
+// each with its options or else the global ones, then by the whole line as
+// a last resort. Without keys the whole line is the key. The last resort
+// is left out with -s, and with -u, which drops lines with equal keys.
+// Text is collated in the order of cfg.Locale.
+func newOrder(cfg Config) (*order, error) {
+	collator, err := newCollator(cfg.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	global := globalKey(cfg)
+	keys := make([]Key, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		if !key.hasOptions() {
+			key = key.withOptions(global)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 && global.hasOptions() {
+		keys = append(keys, global)
+	}
+
+	return &order{
+		keys:       keys,
+		delimiter:  cfg.Delimiter,
+		collator:   collator,
+		lastResort: len(keys) == 0 || !(cfg.Stable || cfg.Unique),
+		reverse:    cfg.Reverse,
+	}, nil
+}
+
+// decorate returns line with the values of its keys; those after the
+// first are stored in rest
+func (o *order) decorate(line string, rest []keyValue) decorated {
+	d := decorated{line: line, rest: rest}
+	for i, key := range o.keys {
+		*d.key(i) = key.value(line, o.delimiter, o.collator)
+	}
+	if o.lastResort && o.collator != nil {
+		d.collated = string(o.collator.Key(line))
+	}
+	return d
+}
+
+// decorateAll decorates lines, with the values of all their keys after
+// the first in one slice. They are sorted as pointers, which move fast.
+func (o *order) decorateAll(lines []string) []*decorated {
+	n := o.restKeys()
+	values := make([]keyValue, len(lines)*n)
+	all := make([]decorated, len(lines))
+	out := make([]*decorated, len(lines))
+	for i, line := range lines {
+		all[i] = o.decorate(line, values[i*n:(i+1)*n:(i+1)*n])
+		out[i] = &all[i]
+	}
+	return out
+}
+
+// compare compares decorated lines without computing their keys again
+func (o *order) compare(a, b *decorated) int {
+	for i := range o.keys {
+		if c := o.keys[i].compare(a.key(i), b.key(i)); c != 0 {
+			return c
+		}
+	}
+	if !o.lastResort {
+		return 0
+	}
+	if o.reverse {
+		return compareText(b.line, b.collated, a.line, a.collated)
+	}
+	return compareText(a.line, a.collated, b.line, b.collated)
+}
+
+// restKeys returns how many keys a decorated line has after the first
+func (o *order) restKeys() int {
+	return max(len(o.keys)-1, 0)
+}
+
+// compareLines compares lines by the last resort alone, for an order
+// without keys or a collator
+func (o *order) compareLines(a, b string) int {
+	if o.reverse {
+		return strings.Compare(b, a)
+	}
+	return strings.Compare(a, b)
+}
+
+// undecorate stores the lines of decorated in lines and returns them
+func undecorate(lines []string, decorated []*decorated) []string {
+	lines = lines[:len(decorated)]
+	for i, d := range decorated {
+		lines[i] = d.line
+	}
+	return lines
+}
+
+// newCollator returns the collation of locale, or nil for the byte order
+// of the C locale
+func newCollator(locale string) (*collate.Collator, error) {
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil, nil
+	}
+	return collate.New(locale)
+}
+
+// outOfOrder reports whether lines compared as c are out of order; with
+// unique equal lines are too
+func outOfOrder(c int, unique bool) bool {
+	return c > 0 || (unique && c == 0)
+}
+
+// rank orders keys that parse after those that do not
+func rank(parsed bool) int {
+	if parsed {
+		return 1
+	}
+	return 0
+}
+
+// months are the names of -M, by their first three letters in uppercase
+var months = [...]string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// parseMonth returns the month whose name s starts with after its blanks,
+// in any case, from 1 for January, or 0 when there is none
+func parseMonth(s string) int {
+	s = strings.TrimLeft(s, " \t")
+	if len(s) < 3 {
+		return 0
+	}
+	for i, month := range months {
+		if toUpper(s[0]) == month[0] && toUpper(s[1]) == month[1] && toUpper(s[2]) == month[2] {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// units are the suffixes of -h by their order, which is the magnitude of
+// their power of 1000 or 1024
+const units = "KMGTPEZY"
+
+// number is a number as -n and -h read it at the start of a key, like GNU
+// sort: after blanks, an optional minus, digits and a decimal point, with
+// no plus sign or exponent. Keys that do not start with one are zero.
+type number struct {
+	negative bool
+	integer  string // digits without leading zeros
+	fraction string // digits without trailing zeros
+	unit     int    // order of the suffix with -h, 0 without one
+}
+
+// parseNumber reads the number at the start of s; with human, the letter
+// after it may be a unit, with or without an i of the IEC prefixes
+func parseNumber(s string, human bool) number {
+	s = strings.TrimLeft(s, " \t")
+	n := number{}
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		n.negative, s = true, rest
+	}
+
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	n.integer, s = strings.TrimLeft(s[:digits], "0"), s[digits:]
+	if rest, ok := strings.CutPrefix(s, "."); ok {
+		digits = len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		n.fraction, s = strings.TrimRight(rest[:digits], "0"), rest[digits:]
+	}
+
+	if n.integer == "" && n.fraction == "" {
+		// zero has no sign or unit
+		return number{}
+	}
+	if human && s != "" {
+		if s[0] == 'k' {
+			n.unit = 1
+		} else {
+			n.unit = strings.IndexByte(units, s[0]) + 1
+		}
+	}
+	return n
+}
+
+// compareNumbers compares numbers by their units, the larger the later,
+// then by value
+func compareNumbers(a, b number) int {
+	if c := cmp.Compare(a.order(), b.order()); c != 0 {
+		return c
+	}
+	if a.negative != b.negative {
+		if a.negative {
+			return -1
+		}
+		return 1
+	}
+
+	c := cmp.Compare(len(a.integer), len(b.integer))
+	if c == 0 {
+		c = strings.Compare(a.integer, b.integer)
+	}
+	if c == 0 {
+		c = strings.Compare(a.fraction, b.fraction)
+	}
+	if a.negative {
+		return -c
+	}
+	return c
+}
+
+// order is the unit of a number signed like it, so that -1K comes before
+// -1 and 1 before 1K
+func (n number) order() int {
+	if n.negative {
+		return -n.unit
+	}
+	return n.unit
+}
+
+// compareGeneral compares keys by their leading floating point numbers, as
+// strtod reads them and parseGeneral returns them. Keys without one come
+// first, then NaNs, then numbers.
+func compareGeneral(va float64, okA bool, vb float64, okB bool) int {
+	switch {
+	case !okA || !okB:
+		return cmp.Compare(rank(okA), rank(okB))
+	case math.IsNaN(va) || math.IsNaN(vb):
+		return cmp.Compare(rank(!math.IsNaN(va)), rank(!math.IsNaN(vb)))
+	}
+	return cmp.Compare(va, vb)
+}
+
+// parseGeneral parses the floating point number at the start of s, after
+// white space: decimal or hexadecimal, with an exponent, or inf or nan
+func parseGeneral(s string) (float64, bool) {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	n := len(s) - len(strings.TrimLeft(s, "+-"))
+	if n > 1 {
+		return 0, false
+	}
+	sign, rest := s[:n], s[n:]
+
+	lower := strings.ToLower(rest)
+	for _, word := range []string{"infinity", "inf", "nan"} {
+		if strings.HasPrefix(lower, word) {
+			v, err := strconv.ParseFloat(sign+word, 64)
+			return v, err == nil
+		}
+	}
+
+	digits, exponent := isDigit, byte('e')
+	if len(lower) > 2 && lower[0] == '0' && lower[1] == 'x' {
+		digits, exponent = isHexDigit, 'p'
+		rest = rest[2:]
+	}
+
+	// the mantissa, with at least one digit
+	end := 0
+	for end < len(rest) && digits(rest[end]) {
+		end++
+	}
+	mantissaDigits := end
+	if end < len(rest) && rest[end] == '.' {
+		end++
+		for end < len(rest) && digits(rest[end]) {
+			end++
+			mantissaDigits++
+		}
+	}
+	if mantissaDigits == 0 {
+		if exponent == 'p' {
+			// strtod reads the 0 of a 0x without digits
+			return 0, true
+		}
+		return 0, false
+	}
+
+	// the exponent counts only if it has digits
+	number := rest[:end]
+	if end < len(rest) && (rest[end]|0x20) == exponent {
+		e := end + 1
+		if e < len(rest) && (rest[e] == '+' || rest[e] == '-') {
+			e++
+		}
+		if e < len(rest) && isDigit(rest[e]) {
+			for e < len(rest) && isDigit(rest[e]) {
+				e++
+			}
+			number = rest[:e]
+		}
+	}
+
+	if exponent == 'p' {
+		number = "0x" + number
+		if !strings.ContainsAny(number, "pP") {
+			number += "p0"
+		}
+	}
+	v, err := strconv.ParseFloat(sign+number, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, false
+	}
+	return v, true
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || 'a' <= c|0x20 && c|0x20 <= 'f'
+}
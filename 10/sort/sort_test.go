@@ -0,0 +1,220 @@
+package sort_test
+
+import (
+	"mysort/sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		cfg     sort.Config
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "simple alphabetical",
+			lines: []string{"banana", "apple", "cherry"},
+			cfg:   sort.Config{},
+			want:  []string{"apple", "banana", "cherry"},
+		},
+		{
+			name:  "numeric sort",
+			lines: []string{"10", "2", "1"},
+			cfg:   sort.Config{Numeric: true},
+			want:  []string{"1", "2", "10"},
+		},
+		{
+			name:  "reverse sort",
+			lines: []string{"a", "c", "b"},
+			cfg:   sort.Config{Reverse: true},
+			want:  []string{"c", "b", "a"},
+		},
+		{
+			name:  "unique values",
+			lines: []string{"apple", "apple", "banana"},
+			cfg:   sort.Config{Unique: true},
+			want:  []string{"apple", "banana"},
+		},
+		{
+			name:  "month sort",
+			lines: []string{"Mar", "Jan", "Feb"},
+			cfg:   sort.Config{Month: true},
+			want:  []string{"Jan", "Feb", "Mar"},
+		},
+		{
+			name:    "check sorted - not sorted",
+			lines:   []string{"a", "c", "b"},
+			cfg:     sort.Config{CheckSorted: true},
+			wantErr: true,
+		},
+		{
+			name:    "check unique",
+			lines:   []string{"a", "b", "b"},
+			cfg:     sort.Config{CheckSorted: true, Unique: true},
+			wantErr: true,
+		},
+		{
+			name:  "sort by column",
+			lines: []string{"3\tb", "1\ta", "2\tc"},
+			cfg:   sort.Config{Keys: []sort.Key{{StartField: 2}}, Delimiter: "\t"},
+			want:  []string{"1\ta", "3\tb", "2\tc"},
+		},
+		{
+			name:  "ignore blanks",
+			lines: []string{"  apple", "banana  ", " cherry "},
+			cfg:   sort.Config{IgnoreBlanks: true},
+			want:  []string{"  apple", "banana  ", " cherry "},
+		},
+		{
+			name:  "human readable numbers",
+			lines: []string{"10K", "2M", "500"},
+			cfg:   sort.Config{Numeric: true, Human: true},
+			want:  []string{"500", "10K", "2M"},
+		},
+		{
+			name:  "human numbers without numeric",
+			lines: []string{"10K", "2Mi", "500", "1G"},
+			cfg:   sort.Config{Human: true},
+			want:  []string{"500", "10K", "2Mi", "1G"},
+		},
+		{
+			name:  "months in any case",
+			lines: []string{"march", "JAN", " Feb"},
+			cfg:   sort.Config{Month: true},
+			want:  []string{"JAN", " Feb", "march"},
+		},
+		{
+			name:  "merge takes the lines as sorted",
+			lines: []string{"b", "a", "a"},
+			cfg:   sort.Config{Merge: true, Unique: true},
+			want:  []string{"b", "a"},
+		},
+		{
+			name:  "locale",
+			lines: []string{"Zebra", "Äpfel", "apple", "Apfel", "apfel", "Ähre", "Affe"},
+			cfg:   sort.Config{Locale: "de_DE.UTF-8"},
+			want:  []string{"Affe", "Ähre", "apfel", "Apfel", "Äpfel", "apple", "Zebra"},
+		},
+		{
+			name:  "locale reverse",
+			lines: []string{"Zebra", "Äpfel", "apple", "Apfel"},
+			cfg:   sort.Config{Locale: "de", Reverse: true},
+			want:  []string{"Zebra", "apple", "Äpfel", "Apfel"},
+		},
+		{
+			name:  "locale fold case unique",
+			lines: []string{"äpfel", "Zebra", "Äpfel", "zebra"},
+			cfg:   sort.Config{Locale: "de", FoldCase: true, Unique: true},
+			want:  []string{"äpfel", "Zebra"},
+		},
+		{
+			name:  "locale dictionary order",
+			lines: []string{"Ö-2", "o_3", "#ä1", "ö1"},
+			cfg:   sort.Config{Locale: "de", Dictionary: true},
+			want:  []string{"#ä1", "ö1", "Ö-2", "o_3"},
+		},
+		{
+			name:  "locale key",
+			lines: []string{"1 Zebra", "2 Äpfel", "3 apple"},
+			cfg:   sort.Config{Locale: "de", Keys: []sort.Key{{StartField: 2, EndField: 2}}},
+			want:  []string{"2 Äpfel", "3 apple", "1 Zebra"},
+		},
+		{
+			name:    "unsupported locale",
+			lines:   []string{"a"},
+			cfg:     sort.Config{Locale: "xx"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sort.Sort(tt.lines, tt.cfg)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    sort.Config
+		wantErr bool
+	}{
+		{
+			name: "flags do not take the next argument",
+			args: []string{"-n", "a.txt", "-r", "b.txt"},
+			want: sort.Config{Numeric: true, Reverse: true, Files: []string{"a.txt", "b.txt"}},
+		},
+		{
+			name: "bundled flags and attached values",
+			args: []string{"-nrk2", "-t:"},
+			want: sort.Config{Numeric: true, Reverse: true, Keys: []sort.Key{{StartField: 2}}, Delimiter: ":"},
+		},
+		{
+			name: "long options",
+			args: []string{"--key=3", "--field-separator", ",", "--unique", "--month-sort"},
+			want: sort.Config{Keys: []sort.Key{{StartField: 3}}, Delimiter: ",", Unique: true, Month: true},
+		},
+		{
+			name: "old delimiter option",
+			args: []string{"-N", ";", "--", "-r"},
+			want: sort.Config{Delimiter: ";", Files: []string{"-r"}},
+		},
+		{
+			name: "text options",
+			args: []string{"-fdi", "--locale=de_DE.UTF-8"},
+			want: sort.Config{FoldCase: true, Dictionary: true, IgnoreNonprinting: true, Locale: "de_DE.UTF-8"},
+		},
+		{
+			name: "long text options",
+			args: []string{"--ignore-case", "--dictionary-order", "--ignore-nonprinting", "--locale", "C"},
+			want: sort.Config{FoldCase: true, Dictionary: true, IgnoreNonprinting: true, Locale: "C"},
+		},
+		{
+			name: "version and general numeric",
+			args: []string{"-V", "--general-numeric-sort"},
+			want: sort.Config{Version: true, General: true},
+		},
+		{
+			name: "quiet check and merge",
+			args: []string{"-C", "-m", "a.txt"},
+			want: sort.Config{CheckSorted: true, Quiet: true, Merge: true, Files: []string{"a.txt"}},
+		},
+		{
+			name: "output and inputs",
+			args: []string{"-zo", "out.txt", "--files0-from", "list"},
+			want: sort.Config{ZeroTerminated: true, Output: "out.txt", Files0From: "list"},
+		},
+		{name: "files0-from and operands", args: []string{"--files0-from=list", "a.txt"}, wantErr: true},
+		{name: "check with output", args: []string{"-C", "-o", "out.txt"}, wantErr: true},
+		{name: "check of two files", args: []string{"-c", "a.txt", "b.txt"}, wantErr: true},
+		{name: "unsupported locale", args: []string{"--locale=xx_XX"}, wantErr: true},
+		{name: "invalid key", args: []string{"-k", "x"}, wantErr: true},
+		{name: "missing value", args: []string{"-k"}, wantErr: true},
+		{name: "unknown option", args: []string{"--nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := sort.ParseConfig(tt.args...)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package sort
+
+// compareVersions compares keys as version numbers, like filevercmp of
+// gnulib that GNU sort -V uses: digit runs compare as numbers, "~" comes
+// before anything, even the end, and a suffix like ".tar.gz" is compared
+// only when the rest is equal. The keys are a and b, whose suffixes start
+// at aPrefix and bPrefix, as versionPrefixLen returns.
+func compareVersions(a string, aPrefix int, b string, bPrefix int) int {
+	switch {
+	case a == "" || b == "":
+		return rank(a != "") - rank(b != "")
+	case a[0] == '.' || b[0] == '.':
+		// ".", "..", then other names starting with a dot, then the rest
+		if a[0] != b[0] {
+			return rank(a[0] != '.') - rank(b[0] != '.')
+		}
+		for _, special := range []string{".", ".."} {
+			if a == special || b == special {
+				return rank(a != special) - rank(b != special)
+			}
+		}
+	}
+
+	if c := compareVersionParts(a[:aPrefix], b[:bPrefix]); c != 0 || (aPrefix == len(a) && bPrefix == len(b)) {
+		return c
+	}
+	return compareVersionParts(a, b)
+}
+
+// versionPrefixLen returns the length of s without its suffix, the
+// longest match of (\.[A-Za-z~][A-Za-z0-9~]*)*$ that is not all of s
+func versionPrefixLen(s string) int {
+	prefix := 0
+	for i := 0; i < len(s); {
+		i++
+		prefix = i
+		for i+1 < len(s) && s[i] == '.' && (isAlpha(s[i+1]) || s[i+1] == '~') {
+			for i += 2; i < len(s) && (isAlnum(s[i]) || s[i] == '~'); i++ {
+			}
+		}
+	}
+	return prefix
+}
+
+// compareVersionParts compares alternating runs of non-digits, by the
+// order of versionOrder, and of digits, by their value
+func compareVersionParts(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			if ca, cb := versionOrder(a, i), versionOrder(b, j); ca != cb {
+				return ca - cb
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && j < len(b) && isDigit(a[i]) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// versionOrder orders the byte of s at i: the end comes after "~" and
+// before everything else, then digits, letters and other bytes
+func versionOrder(s string, i int) int {
+	if i >= len(s) {
+		return -1
+	}
+	switch c := s[i]; {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -2
+	default:
+		return int(c) + 256
+	}
+}
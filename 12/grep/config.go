@@ -0,0 +1,90 @@
+package grep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"getopt"
+)
+
+// Config for grep
+type Config struct {
+	After       int
+	Before      int
+	Context     int
+	CountOnly   bool
+	IgnoreCase  bool
+	InvertMatch bool
+	Fixed       bool
+	LineNum     bool
+	Pattern     string
+	Files       []string
+}
+
+// options of grep; --pattern is the name mygrep first used for -e
+var options = []getopt.Option{
+	{Short: 'A', Long: "after-context", HasArg: true},
+	{Short: 'B', Long: "before-context", HasArg: true},
+	{Short: 'C', Long: "context", HasArg: true},
+	{Short: 'c', Long: "count"},
+	{Short: 'i', Long: "ignore-case"},
+	{Short: 'v', Long: "invert-match"},
+	{Short: 'F', Long: "fixed-strings"},
+	{Short: 'n', Long: "line-number"},
+	{Short: 'e', Long: "regexp", HasArg: true},
+	{Long: "pattern", HasArg: true},
+}
+
+// ParseConfig parses command line arguments into Config. The pattern is
+// the first operand, or the value of -e; the other operands are files.
+func ParseConfig(args ...string) (Config, error) {
+	cfg := Config{}
+	patternSet := false
+
+	operands, err := getopt.Parse(args, options, func(opt getopt.Option, value string) error {
+		switch opt.Short {
+		case 'A', 'B', 'C':
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s: invalid context length argument", value)
+			}
+			switch opt.Short {
+			case 'A':
+				cfg.After = n
+			case 'B':
+				cfg.Before = n
+			default:
+				cfg.Context = n
+			}
+		case 'c':
+			cfg.CountOnly = true
+		case 'i':
+			cfg.IgnoreCase = true
+		case 'v':
+			cfg.InvertMatch = true
+		case 'F':
+			cfg.Fixed = true
+		case 'n':
+			cfg.LineNum = true
+		default: // -e and --pattern
+			cfg.Pattern = value
+			patternSet = true
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, err
+	}
+
+	if !patternSet {
+		if len(operands) == 0 {
+			return cfg, errors.New("missing pattern")
+		}
+		cfg.Pattern, operands = operands[0], operands[1:]
+	}
+	if len(operands) > 0 {
+		cfg.Files = operands
+	}
+	return cfg, nil
+}
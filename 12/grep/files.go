@@ -0,0 +1,85 @@
+package grep
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// OpenFunc opens a named input; "-" stands for stdin
+type OpenFunc func(name string) (io.ReadCloser, error)
+
+// GrepFiles greps the files of cfg.Files in turn, or stdin when there are
+// none, each opened with open. With several files every line is prefixed
+// by the name of its file, "(standard input)" for stdin. Files that cannot
+// be read are reported in the error, one "name: reason" each, after the
+// others are searched.
+func GrepFiles(ctx context.Context, cfg Config, open OpenFunc) ([]string, error) {
+	names := cfg.Files
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var result []string
+	var errs []error
+	for _, name := range names {
+		lines, err := readFile(open, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		matched, err := GrepContext(ctx, lines, cfg)
+		if err != nil {
+			return nil, err
+		}
+		label := name
+		if name == "-" {
+			label = "(standard input)"
+		}
+		for _, line := range matched {
+			if len(names) > 1 {
+				line = label + ":" + line
+			}
+			result = append(result, line)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// readFile reads the lines of the input named name, without their ends
+func readFile(open OpenFunc, name string) ([]string, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, fileError(name, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fileError(name, err)
+		}
+	}
+}
+
+// fileError reports err of the input name without the operation and path
+// of an fs.PathError, like "a.txt: no such file or directory"
+func fileError(name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
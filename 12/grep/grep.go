@@ -1,13 +1,23 @@
 package grep
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 )
 
+// checkInterval is how many lines are processed between context checks
+const checkInterval = 1024
+
 // Grep filters lines according to cfg and returns a new slice.
 func Grep(lines []string, cfg Config) []string {
+	result, _ := GrepContext(context.Background(), lines, cfg)
+	return result
+}
+
+// GrepContext is like Grep but stops with ctx.Err() once ctx is done.
+func GrepContext(ctx context.Context, lines []string, cfg Config) ([]string, error) {
 	var matcher func(string) bool
 
 	if cfg.Fixed {
@@ -35,6 +45,9 @@ func Grep(lines []string, cfg Config) []string {
 
 	matches := make(map[int]struct{})
 	for i, line := range lines {
+		if i%checkInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		ok := matcher(line)
 		if cfg.InvertMatch {
 			ok = !ok
@@ -45,7 +58,7 @@ func Grep(lines []string, cfg Config) []string {
 	}
 
 	if cfg.CountOnly {
-		return []string{fmt.Sprintf("%d", len(matches))}
+		return []string{fmt.Sprintf("%d", len(matches))}, nil
 	}
 
 	after := cfg.After
@@ -81,5 +94,5 @@ func Grep(lines []string, cfg Config) []string {
 		}
 	}
 
-	return result
+	return result, nil
 }
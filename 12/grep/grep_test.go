@@ -0,0 +1,193 @@
+package grep_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"mygrep/grep"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrep(t *testing.T) {
+	lines := []string{
+		"Hello World",
+		"foo bar",
+		"HELLO again",
+		"something else",
+		"foo HELLO",
+	}
+
+	tests := []struct {
+		name string
+		cfg  grep.Config
+		want []string
+	}{
+		{
+			name: "simple match",
+			cfg:  grep.Config{Pattern: "Hello"},
+			want: []string{"Hello World"},
+		},
+		{
+			name: "ignore case",
+			cfg:  grep.Config{Pattern: "hello", IgnoreCase: true},
+			want: []string{"Hello World", "HELLO again", "foo HELLO"},
+		},
+		{
+			name: "invert match",
+			cfg:  grep.Config{Pattern: "foo", InvertMatch: true},
+			want: []string{"Hello World", "HELLO again", "something else"},
+		},
+		{
+			name: "fixed string match",
+			cfg:  grep.Config{Pattern: "foo", Fixed: true},
+			want: []string{"foo bar", "foo HELLO"},
+		},
+		{
+			name: "count only",
+			cfg:  grep.Config{Pattern: "HELLO", IgnoreCase: true, CountOnly: true},
+			want: []string{"3"},
+		},
+		{
+			name: "line numbers",
+			cfg:  grep.Config{Pattern: "foo", Fixed: true, LineNum: true},
+			want: []string{"2:foo bar", "5:foo HELLO"},
+		},
+		{
+			name: "after context",
+			cfg:  grep.Config{Pattern: "foo bar", After: 1},
+			want: []string{"foo bar", "HELLO again"},
+		},
+		{
+			name: "before context",
+			cfg:  grep.Config{Pattern: "foo HELLO", Before: 1},
+			want: []string{"something else", "foo HELLO"},
+		},
+		{
+			name: "context both sides (C)",
+			cfg:  grep.Config{Pattern: "HELLO again", Context: 1},
+			want: []string{"foo bar", "HELLO again", "something else"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := grep.Grep(lines, tt.cfg)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    grep.Config
+		wantErr string
+	}{
+		{
+			name: "bundled flags",
+			args: []string{"-in", "foo", "a.txt", "b.txt"},
+			want: grep.Config{IgnoreCase: true, LineNum: true, Pattern: "foo", Files: []string{"a.txt", "b.txt"}},
+		},
+		{
+			name: "attached value",
+			args: []string{"-A3", "-B", "1", "foo"},
+			want: grep.Config{After: 3, Before: 1, Pattern: "foo"},
+		},
+		{
+			name: "long options",
+			args: []string{"--ignore-case", "--context=2", "--count", "foo"},
+			want: grep.Config{IgnoreCase: true, Context: 2, CountOnly: true, Pattern: "foo"},
+		},
+		{
+			name: "pattern option",
+			args: []string{"a.txt", "-e", "-v"},
+			want: grep.Config{Pattern: "-v", Files: []string{"a.txt"}},
+		},
+		{
+			name: "end of options",
+			args: []string{"-F", "--", "-x-"},
+			want: grep.Config{Fixed: true, Pattern: "-x-"},
+		},
+		{
+			name:    "missing value",
+			args:    []string{"foo", "-A"},
+			wantErr: "option requires an argument -- 'A'",
+		},
+		{
+			name:    "invalid context",
+			args:    []string{"-C", "x", "foo"},
+			wantErr: "x: invalid context length argument",
+		},
+		{
+			name:    "unknown option",
+			args:    []string{"-z", "foo"},
+			wantErr: "invalid option -- 'z'",
+		},
+		{
+			name:    "missing pattern",
+			args:    []string{"-i"},
+			wantErr: "missing pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := grep.ParseConfig(tt.args...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestGrepFiles(t *testing.T) {
+	files := map[string]string{"-": "foo in\n", "a.txt": "foo\nbar\n", "b.txt": "food"}
+	open := func(name string) (io.ReadCloser, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+
+	tests := []struct {
+		name    string
+		cfg     grep.Config
+		want    []string
+		wantErr string
+	}{
+		{name: "stdin", cfg: grep.Config{Pattern: "foo"}, want: []string{"foo in"}},
+		{name: "one file", cfg: grep.Config{Pattern: "foo", Files: []string{"a.txt"}}, want: []string{"foo"}},
+		{
+			name: "files",
+			cfg:  grep.Config{Pattern: "foo", LineNum: true, Files: []string{"a.txt", "-", "b.txt"}},
+			want: []string{"a.txt:1:foo", "(standard input):1:foo in", "b.txt:1:food"},
+		},
+		{name: "count", cfg: grep.Config{Pattern: "o", CountOnly: true, Files: []string{"a.txt", "b.txt"}}, want: []string{"a.txt:1", "b.txt:1"}},
+		{
+			name:    "missing files",
+			cfg:     grep.Config{Pattern: "foo", Files: []string{"x.txt", "b.txt", "y.txt"}},
+			want:    []string{"b.txt:food"},
+			wantErr: "x.txt: file does not exist\ny.txt: file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := grep.GrepFiles(context.Background(), tt.cfg, open)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,45 +1,37 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
-	"mycut/internal/config"
-	"mycut/internal/cut"
+	"mycut/cut"
 )
 
 func main() {
-	cfg := config.Config{}
-	flag.StringVar(&cfg.Fields, "f", "", "fields to select (e.g. 1,3-5)")
-	flag.StringVar(&cfg.Delimiter, "d", "\t", "delimiter (default tab)")
-	flag.BoolVar(&cfg.Separated, "s", false, "only print lines with delimiter")
-
-	flag.Parse()
-
-	if cfg.Fields == "" {
-		fmt.Fprintln(os.Stderr, "error: -f flag is required")
-		os.Exit(1)
+	cfg, err := cut.ParseConfig(os.Args[1:]...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cut:", err)
+		os.Exit(2)
 	}
 
-	var lines []string
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintln(os.Stderr, "read error:", err)
-		os.Exit(1)
+	result, err := cut.CutFiles(cfg, open)
+	for _, line := range result {
+		fmt.Println(line)
 	}
-
-	result, err := cut.Cut(lines, cfg)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "cut error:", err)
+		for _, msg := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintln(os.Stderr, "cut:", msg)
+		}
 		os.Exit(1)
 	}
+}
 
-	for _, line := range result {
-		fmt.Println(line)
+// open opens a named input, "-" for stdin
+func open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
 	}
+	return os.Open(name)
 }
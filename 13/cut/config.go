@@ -0,0 +1,50 @@
+package cut
+
+import (
+	"errors"
+
+	"getopt"
+)
+
+// Config for cut
+type Config struct {
+	Fields    string
+	Delimiter string
+	Separated bool
+	Files     []string
+}
+
+// options of cut
+var options = []getopt.Option{
+	{Short: 'f', Long: "fields", HasArg: true},
+	{Short: 'd', Long: "delimiter", HasArg: true},
+	{Short: 's', Long: "only-delimited"},
+}
+
+// ParseConfig parses the command line arguments
+func ParseConfig(args ...string) (Config, error) {
+	cfg := Config{Delimiter: "\t"}
+
+	operands, err := getopt.Parse(args, options, func(opt getopt.Option, value string) error {
+		switch opt.Short {
+		case 'f':
+			cfg.Fields = value
+		case 'd':
+			cfg.Delimiter = value
+		case 's':
+			cfg.Separated = true
+		}
+		return nil
+	})
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.Fields == "" {
+		return cfg, errors.New("you must specify a list of fields")
+	}
+
+	if len(operands) > 0 {
+		cfg.Files = operands
+	}
+	return cfg, nil
+}
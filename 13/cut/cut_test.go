@@ -0,0 +1,156 @@
+package cut_test
+
+import (
+	"io"
+	"io/fs"
+	"mycut/cut"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		cfg     cut.Config
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single column",
+			lines: []string{"a\tb\tc", "1\t2\t3"},
+			cfg:   cut.Config{Fields: "1", Delimiter: "\t"},
+			want:  []string{"a", "1"},
+		},
+		{
+			name:  "multiple columns",
+			lines: []string{"a:b:c:d", "1:2:3:4"},
+			cfg:   cut.Config{Fields: "1,3-4", Delimiter: ":"},
+			want:  []string{"a:c:d", "1:3:4"},
+		},
+		{
+			name:  "range columns",
+			lines: []string{"x y z w", "1 2 3 4"},
+			cfg:   cut.Config{Fields: "2-3", Delimiter: " "},
+			want:  []string{"y z", "2 3"},
+		},
+		{
+			name:  "ignore lines without delimiter -s",
+			lines: []string{"a:b", "no_delim"},
+			cfg:   cut.Config{Fields: "1", Delimiter: ":", Separated: true},
+			want:  []string{"a"},
+		},
+		{
+			name:  "fields out of range",
+			lines: []string{"a:b:c"},
+			cfg:   cut.Config{Fields: "2,5", Delimiter: ":"},
+			want:  []string{"b"},
+		},
+		{
+			name:    "invalid field",
+			lines:   []string{"a:b:c"},
+			cfg:     cut.Config{Fields: "x", Delimiter: ":"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			lines:   []string{"a:b:c"},
+			cfg:     cut.Config{Fields: "3-1", Delimiter: ":"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cut.Cut(tt.lines, tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    cut.Config
+		wantErr bool
+	}{
+		{
+			name: "attached values",
+			args: []string{"-d:", "-f1,3", "a.txt"},
+			want: cut.Config{Fields: "1,3", Delimiter: ":", Files: []string{"a.txt"}},
+		},
+		{
+			name: "bundled flags",
+			args: []string{"-sf", "2"},
+			want: cut.Config{Fields: "2", Delimiter: "\t", Separated: true},
+		},
+		{
+			name: "long options",
+			args: []string{"--delimiter", ",", "--fields=2-3", "--only-delimited", "--", "-"},
+			want: cut.Config{Fields: "2-3", Delimiter: ",", Separated: true, Files: []string{"-"}},
+		},
+		{name: "missing fields", args: []string{"-s"}, wantErr: true},
+		{name: "missing value", args: []string{"-f"}, wantErr: true},
+		{name: "unknown option", args: []string{"-f", "1", "-x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := cut.ParseConfig(tt.args...)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, cfg)
+			}
+		})
+	}
+}
+
+func TestCutFiles(t *testing.T) {
+	files := map[string]string{"-": "s:1\n", "a.txt": "a:1\nb:2\n", "b.txt": "c:3"}
+	open := func(name string) (io.ReadCloser, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+
+	tests := []struct {
+		name    string
+		cfg     cut.Config
+		want    []string
+		wantErr string
+	}{
+		{name: "stdin", cfg: cut.Config{Fields: "1", Delimiter: ":"}, want: []string{"s"}},
+		{name: "files", cfg: cut.Config{Fields: "2", Delimiter: ":", Files: []string{"a.txt", "-", "b.txt"}}, want: []string{"1", "2", "1", "3"}},
+		{
+			name:    "missing files",
+			cfg:     cut.Config{Fields: "1", Delimiter: ":", Files: []string{"x.txt", "b.txt", "y.txt"}},
+			want:    []string{"c"},
+			wantErr: "x.txt: file does not exist\ny.txt: file does not exist",
+		},
+		{name: "invalid fields", cfg: cut.Config{Fields: "x", Files: []string{"x.txt"}}, wantErr: "invalid field: x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cut.CutFiles(tt.cfg, open)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
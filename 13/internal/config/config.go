@@ -1,8 +0,0 @@
-package config
-
-// Config for cut
-type Config struct {
-	Fields    string
-	Delimiter string
-	Separated bool
-}
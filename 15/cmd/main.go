@@ -1,9 +1,43 @@
 package main
 
-import "minishell/internal/shell"
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"minishell/shell"
+)
 
 func main() {
-	minishell := shell.Shell{}
+	restricted := flag.Bool("restricted", false, "refuse cd, absolute redirections, PATH changes and commands not allowed")
+	allow := flag.String("allow", "", "comma-separated external commands allowed in restricted mode")
+	traceJSON := flag.String("trace-json", "", "write a JSON record of every executed command to `FILE`")
+	flag.Parse()
+
+	var opts []shell.Option
+	if *traceJSON != "" {
+		f, err := os.Create(*traceJSON)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		opts = append(opts, shell.WithTraceJSON(f))
+	}
+	if *restricted {
+		var names []string
+		if *allow != "" {
+			names = strings.Split(*allow, ",")
+		}
+		opts = append(opts, shell.WithRestricted(names...))
+	}
+
+	minishell, err := shell.New(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	minishell.Run()
 }
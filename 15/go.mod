@@ -2,10 +2,23 @@ module minishell
 
 go 1.25.0
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/stretchr/testify v1.11.1
+	mycut v0.0.0
+	mygrep v0.0.0
+	mysort v0.0.0
+)
 
 require (
+	getopt v0.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	getopt => ../getopt
+	mycut => ../13
+	mygrep => ../12
+	mysort => ../10
+)
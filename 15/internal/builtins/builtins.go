@@ -1,93 +1,281 @@
 package builtins
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
+	"slices"
 	"strings"
-	"syscall"
+	"sync"
 
-	"minishell/internal/builtins/cut"
-	"minishell/internal/builtins/grep"
-	"minishell/internal/builtins/sort"
-	"minishell/internal/models"
+	"mycut/cut"
+	"mygrep/grep"
+	"mysort/sort"
+
+	"minishell/internal/env"
+	"minishell/internal/jobs"
+	"minishell/internal/procfs"
 )
 
 // ErrCmdNotFound is returned when a command is not found
 var ErrCmdNotFound = fmt.Errorf("builtins: command not found")
 
+// ErrRestricted is returned for what a restricted shell refuses to do
+var ErrRestricted = errors.New("restricted")
+
+// IO holds the standard streams of a builtin invocation
+type IO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ExtraFiles are the open descriptors from 3 on, as in exec.Cmd;
+	// builtins ignore them but external commands inherit them.
+	ExtraFiles []*os.File
+}
+
+// Func is the implementation of a builtin command
+type Func func(ctx context.Context, b *Builtins, stdio IO, args []string) error
+
+// registry maps the name of every builtin to its implementation. Entries
+// without an implementation are run by the shell itself.
+var registry = map[string]Func{
+	"cd": func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		return b.Cd(args...)
+	},
+	"pwd": text((*Builtins).Pwd),
+	"echo": func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		out, err := b.Echo(args...)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdio.Stdout, out)
+		return err
+	},
+	"kill":    text((*Builtins).Kill),
+	"ps":      text((*Builtins).Ps),
+	"hash":    text((*Builtins).Hash),
+	"type":    text((*Builtins).Type),
+	"which":   text((*Builtins).Which),
+	"command": text((*Builtins).Command),
+	"set":     text((*Builtins).Set),
+	"shopt":   text((*Builtins).Shopt),
+	"trap":    text((*Builtins).Trap),
+	"exec":    nil,
+	"read":    stream((*Builtins).Read),
+	"printf": func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		out, err := b.Printf(args...)
+		if _, writeErr := io.WriteString(stdio.Stdout, out); writeErr != nil && err == nil {
+			err = writeErr
+		}
+		return err
+	},
+	"grep":    filter((*Builtins).Grep),
+	"cut":     filter((*Builtins).Cut),
+	"sort":    stream((*Builtins).Sort),
+	"cat":     stream((*Builtins).Cat),
+	"head":    stream((*Builtins).Head),
+	"tail":    stream((*Builtins).Tail),
+	"wc":      stream((*Builtins).Wc),
+	"tee":     stream((*Builtins).Tee),
+	"uniq":    stream((*Builtins).Uniq),
+	"tr":      stream((*Builtins).Tr),
+	"timeout": nil,
+	"xargs":   nil,
+	"find":    nil,
+}
+
 // Builtins is a struct that represents the built-in commands
 type Builtins struct {
-	processes []models.Process
-}
-
-// Run executes a builtin command and returns its output
-func (b *Builtins) Run(cmd string, input string, args ...string) (string, error) {
-	switch cmd {
-	case "cd":
-		return "", b.Cd(args...)
-	case "pwd":
-		return b.Pwd()
-	case "echo":
-		return b.Echo(args...)
-	case "kill":
-		return "", b.Kill(args...)
-	case "ps":
-		return b.Ps(), nil
-	case "grep":
-		return b.Grep(input, args...), nil
-	case "cut":
-		return b.Cut(input, args...)
-	case "sort":
-		return b.Sort(input, args...)
-	default:
-		return "", ErrCmdNotFound
+	env     *env.Env
+	enabled map[string]Func
+
+	mu      sync.Mutex
+	dir     string
+	options map[string]bool
+	traps   map[string]string
+	pumps   map[io.Reader]*bytePump // pumps read the stdin of read, see readByteFunc
+
+	jobs       jobs.Table
+	hash       pathCache
+	proc       procfs.FS
+	restricted bool
+}
+
+// New returns all builtins, working on the given variables and starting
+// in dir
+func New(vars *env.Env, dir string) *Builtins {
+	return &Builtins{
+		env:     vars,
+		enabled: registry,
+		dir:     filepath.Clean(dir),
+		proc:    procfs.Default,
+	}
+}
+
+// Only restricts the builtins to the given names
+func (b *Builtins) Only(names ...string) error {
+	enabled := make(map[string]Func, len(names))
+	for _, name := range names {
+		fn, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("unknown builtin: %s", name)
+		}
+		enabled[name] = fn
+	}
+	b.enabled = enabled
+	return nil
+}
+
+// Restrict keeps the builtins from writing files out of the shell's
+// directory
+func (b *Builtins) Restrict() {
+	b.restricted = true
+}
+
+// CheckWrite returns ErrRestricted for a file the builtins may not write
+// when restricted: one named by an absolute path or out of the shell's
+// directory
+func (b *Builtins) CheckWrite(name string) error {
+	if !b.restricted {
+		return nil
+	}
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("%s: %w", name, ErrRestricted)
+	}
+	return nil
+}
+
+// Names returns the sorted names of the enabled builtins
+func (b *Builtins) Names() []string {
+	names := make([]string, 0, len(b.enabled))
+	for name := range b.enabled {
+		names = append(names, name)
 	}
+	slices.Sort(names)
+	return names
+}
+
+// IsBuiltin reports whether name is an enabled builtin command
+func (b *Builtins) IsBuiltin(name string) bool {
+	_, ok := b.enabled[name]
+	return ok
+}
+
+// Run executes a builtin command
+func (b *Builtins) Run(ctx context.Context, cmd string, stdio IO, args ...string) error {
+	fn := b.enabled[cmd]
+	if fn == nil {
+		return ErrCmdNotFound
+	}
+	return fn(ctx, b, stdio, args)
+}
+
+// Env returns the shell variables
+func (b *Builtins) Env() *env.Env {
+	return b.env
+}
+
+// Dir returns the current directory of the shell
+func (b *Builtins) Dir() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dir
+}
+
+// Abs makes a path relative to the shell's current directory absolute
+func (b *Builtins) Abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(b.Dir(), path)
 }
 
 // Cd changes the current directory
 func (b *Builtins) Cd(args ...string) error {
 	path := ""
 	if len(args) == 0 {
-		path = os.Getenv("HOME")
+		path = b.env.Get("HOME")
 	} else {
 		path = args[0]
 	}
-	return os.Chdir(path)
+
+	dir := b.Abs(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("cd: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cd: %s: not a directory", path)
+	}
+
+	b.mu.Lock()
+	old := b.dir
+	b.dir = dir
+	b.mu.Unlock()
+
+	b.env.Set("OLDPWD", old)
+	b.env.Set("PWD", dir)
+	return nil
 }
 
-// Pwd prints the current directory
-func (b *Builtins) Pwd() (string, error) {
-	dir, err := os.Getwd()
+// Pwd prints the current directory. With -L (the default) the path is the
+// one cd followed, with -P symbolic links are resolved.
+func (b *Builtins) Pwd(args ...string) (string, error) {
+	physical := false
+	for _, arg := range args {
+		switch arg {
+		case "-L":
+			physical = false
+		case "-P":
+			physical = true
+		default:
+			return "", fmt.Errorf("pwd: %s: invalid option", arg)
+		}
+	}
+
+	if !physical {
+		return b.Dir(), nil
+	}
+	dir, err := filepath.EvalSymlinks(b.Dir())
 	if err != nil {
-		return "", fmt.Errorf("pwd error: %w", err)
+		return "", fmt.Errorf("pwd: %w", err)
 	}
 	return dir, nil
 }
 
-// Echo prints the arguments
+// Echo prints the arguments. Leading arguments made of the flags n, e and
+// E, like -n -e or -neE, are options: -n drops the newline, -e interprets
+// backslash escapes and -E, the default unless xpg_echo is set, does not.
+// The escape \c ends the output.
 func (b *Builtins) Echo(args ...string) (string, error) {
 	noNewline := false
-	interpretEscapes := false
-	outArgs := []string{}
+	interpretEscapes := b.Option("xpg_echo")
 
-	for i, arg := range args {
-		if i == 0 && arg == "-n" {
-			noNewline = true
-			continue
+	for len(args) > 0 && isEchoFlags(args[0]) {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 'n':
+				noNewline = true
+			case 'e':
+				interpretEscapes = true
+			case 'E':
+				interpretEscapes = false
+			}
 		}
-		if i == 0 && arg == "-e" {
-			interpretEscapes = true
-			continue
-		}
-		outArgs = append(outArgs, arg)
+		args = args[1:]
 	}
 
-	out := strings.Join(outArgs, " ")
+	out := strings.Join(args, " ")
 	if interpretEscapes {
-		out = strings.ReplaceAll(out, `\n`, "\n")
-		out = strings.ReplaceAll(out, `\t`, "\t")
-		out = strings.ReplaceAll(out, `\r`, "\r")
+		var stop bool
+		if out, stop = unescape(out, echoEscapes); stop {
+			return out, nil
+		}
 	}
 
 	if !noNewline {
@@ -97,77 +285,152 @@ func (b *Builtins) Echo(args ...string) (string, error) {
 	return out, nil
 }
 
-// Kill sends a signal to a process
-func (b *Builtins) Kill(args ...string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("usage: kill [-SIGNAL] pid")
+// isEchoFlags reports whether arg is an option of echo rather than text
+func isEchoFlags(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
 	}
+	return strings.Trim(arg[1:], "neE") == ""
+}
 
-	sig := syscall.SIGTERM
-	pidArg := args[0]
+// Jobs returns the table of processes started by the shell
+func (b *Builtins) Jobs() *jobs.Table {
+	return &b.jobs
+}
 
-	if strings.HasPrefix(pidArg, "-") && len(args) > 1 {
-		switch pidArg {
-		case "-9":
-			sig = syscall.SIGKILL
-		case "-15":
-			sig = syscall.SIGTERM
-		default:
-			return fmt.Errorf("unsupported signal: %s", pidArg)
-		}
-		pidArg = args[1]
+// Grep filters the lines of the files, or of stdin if none are given. With
+// several files each line is prefixed by the name of its file. Files that
+// cannot be read fail with status 2, after the others are searched.
+func (b *Builtins) Grep(ctx context.Context, stdin io.Reader, args ...string) ([]string, error) {
+	cfg, err := grep.ParseConfig(args...)
+	if err != nil {
+		return nil, usageError("grep", "%v", err)
+	}
+	result, err := grep.GrepFiles(ctx, cfg, b.opener(ctx, stdin))
+	if err != nil && ctx.Err() == nil {
+		err = &StatusError{Status: 2, Err: prefixEach("grep", err)}
 	}
+	return result, err
+}
 
-	pid, err := strconv.Atoi(pidArg)
+// Cut extracts columns from the lines of the files, or of stdin
+func (b *Builtins) Cut(ctx context.Context, stdin io.Reader, args ...string) ([]string, error) {
+	cfg, err := cut.ParseConfig(args...)
 	if err != nil {
-		return fmt.Errorf("invalid pid: %s", pidArg)
+		return nil, usageError("cut", "%v", err)
 	}
-
-	proc, err := os.FindProcess(pid)
+	result, err := cut.CutFiles(cfg, b.opener(ctx, stdin))
 	if err != nil {
-		return err
+		err = prefixEach("cut", err)
 	}
-	return proc.Signal(sig)
+	return result, err
 }
 
-// NewProcess adds a new process to the list of processes
-func (b *Builtins) NewProcess(pid int, cmd string) {
-	b.processes = append(b.processes, models.Process{PID: pid, Cmd: cmd})
+// Sort sorts the lines of the files, or of stdin. Input that does not fit
+// in the buffer of -S is sorted in temporary files. With -c it reports the
+// first line out of order, and -C only fails.
+func (b *Builtins) Sort(ctx context.Context, stdio IO, args ...string) error {
+	cfg, err := sort.ParseConfig(args...)
+	if err != nil {
+		return usageError("sort", "%v", err)
+	}
+	// inputs that cannot be read fail like in GNU sort
+	fail := func(err error) error {
+		return &StatusError{Status: 2, Err: fmt.Errorf("sort: %w", err)}
+	}
+	if cfg.TempDir != "" {
+		if err := b.CheckWrite(cfg.TempDir); err != nil {
+			return fail(err)
+		}
+		cfg.TempDir = b.Abs(cfg.TempDir)
+	}
+
+	var output *sort.Output
+	if cfg.Output != "" {
+		if err := b.CheckWrite(cfg.Output); err != nil {
+			return fail(err)
+		}
+		output = sort.NewOutput(b.Abs(cfg.Output))
+	}
+
+	inputs, closeInputs, err := sort.OpenInputs(cfg, output, b.opener(ctx, stdio.Stdin))
+	if err != nil {
+		return fail(err)
+	}
+	defer closeInputs()
+	for i, r := range inputs {
+		inputs[i] = contextReader{ctx, r}
+	}
+
+	if output != nil {
+		err = output.Finish(sort.SortStream(ctx, output, cfg, inputs...))
+	} else {
+		err = sort.SortStream(ctx, stdio.Stdout, cfg, inputs...)
+	}
+	if errors.Is(err, sort.ErrNotSorted) {
+		if cfg.Quiet {
+			return &StatusError{Status: 1}
+		}
+		return fmt.Errorf("sort: %w", err)
+	}
+	return err
 }
 
-// RemoveProcess removes a process from the list of processes
-func (b *Builtins) RemoveProcess(pid int) {
-	for i, p := range b.processes {
-		if p.PID == pid {
-			b.processes = append(b.processes[:i], b.processes[i+1:]...)
-			return
+// opener opens the inputs of the shared grep, cut and sort packages
+// relative to the shell's directory; "-" is stdin
+func (b *Builtins) opener(ctx context.Context, stdin io.Reader) func(name string) (io.ReadCloser, error) {
+	return func(name string) (io.ReadCloser, error) {
+		if name != "-" {
+			return os.Open(b.Abs(name))
 		}
+		if stdin == nil {
+			stdin = strings.NewReader("")
+		}
+		return io.NopCloser(contextReader{ctx, stdin}), nil
 	}
 }
 
-// Ps returns the list of processes
-func (b *Builtins) Ps() string {
-	output := "PID    CMD"
-	for _, p := range b.processes {
-		output += "\n" + fmt.Sprintf("%-6d %s", p.PID, p.Cmd)
+// text adapts a builtin that returns its whole output as a string
+func text(fn func(b *Builtins, args ...string) (string, error)) Func {
+	return func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		out, err := fn(b, args...)
+		if writeErr := writeOutput(stdio.Stdout, out); writeErr != nil && err == nil {
+			err = writeErr
+		}
+		return err
 	}
-	return output
 }
 
-// Grep filters lines
-func (b *Builtins) Grep(lines string, args ...string) string {
-	result := grep.Grep(strings.Split(lines, "\n"), grep.ParseConfig(args...))
-	return strings.Join(result, "\n")
+// filter adapts a builtin that transforms the lines of its input
+func filter(fn func(b *Builtins, ctx context.Context, stdin io.Reader, args ...string) ([]string, error)) Func {
+	return func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		result, err := fn(b, ctx, stdio.Stdin, args...)
+		if len(result) == 0 {
+			return err
+		}
+		// what was read before an input failed is still written
+		if _, writeErr := io.WriteString(stdio.Stdout, strings.Join(result, "\n")+"\n"); writeErr != nil && err == nil {
+			err = writeErr
+		}
+		return err
+	}
 }
 
-// Cut extracts columns from lines
-func (b *Builtins) Cut(lines string, args ...string) (string, error) {
-	result, err := cut.Cut(strings.Split(lines, "\n"), cut.ParseConfig(args...))
-	return strings.Join(result, "\n"), err
+// stream adapts a builtin that reads and writes the streams itself
+func stream(fn func(b *Builtins, ctx context.Context, stdio IO, args ...string) error) Func {
+	return func(ctx context.Context, b *Builtins, stdio IO, args []string) error {
+		return fn(b, ctx, stdio, args...)
+	}
 }
 
-// Sort sorts lines
-func (b *Builtins) Sort(lines string, args ...string) (string, error) {
-	result, err := sort.Sort(strings.Split(lines, "\n"), sort.ParseConfig(args...))
-	return strings.Join(result, "\n"), err
+// writeOutput writes the output of a builtin, ending it with a newline
+func writeOutput(w io.Writer, out string) error {
+	if out == "" {
+		return nil
+	}
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
 }
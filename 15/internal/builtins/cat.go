@@ -0,0 +1,59 @@
+package builtins
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Cat copies the files, or stdin, to stdout. With -n the output lines are
+// numbered across all the files; -u is accepted and ignored since the
+// output is never held back.
+func (b *Builtins) Cat(ctx context.Context, stdio IO, args ...string) error {
+	number := false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		for _, flag := range opt[1:] {
+			switch flag {
+			case 'n':
+				number = true
+			case 'u':
+			default:
+				return usageError("cat", "-%c: invalid option", flag)
+			}
+		}
+	}
+
+	if !number {
+		return b.eachInput(ctx, stdio.Stdin, "cat", args, func(name string, r io.Reader) error {
+			_, err := io.Copy(stdio.Stdout, r)
+			return err
+		})
+	}
+
+	out := bufio.NewWriter(stdio.Stdout)
+	lineNo := 0
+	atStart := true
+	err := b.eachInput(ctx, stdio.Stdin, "cat", args, func(name string, r io.Reader) error {
+		return eachLine(r, func(line []byte) error {
+			// a line left unfinished by one file goes on in the next
+			if atStart {
+				lineNo++
+				fmt.Fprintf(out, "%6d\t", lineNo)
+			}
+			atStart = line[len(line)-1] == '\n'
+			_, err := out.Write(line)
+			return err
+		})
+	})
+	if flushErr := out.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
+}
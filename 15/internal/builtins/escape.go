@@ -0,0 +1,121 @@
+package builtins
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapeStyle selects the dialect of backslash escapes
+type escapeStyle int
+
+const (
+	// printfEscapes are those of a printf format: octal is \NNN and \c
+	// is kept as it is
+	printfEscapes escapeStyle = iota
+	// echoEscapes are those of echo -e and printf %b: octal is \0NNN and
+	// \c ends the output
+	echoEscapes
+)
+
+// unescape expands the backslash escapes of s: \a \b \e \f \n \r \t \v \\,
+// octal, \xHH, \uHHHH and \UHHHHHHHH. Unknown escapes are kept. It reports
+// whether \c asked to stop all further output.
+func unescape(s string, style escapeStyle) (string, bool) {
+	if !strings.Contains(s, `\`) {
+		return s, false
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'e', 'E':
+			sb.WriteByte(0x1b)
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '\\':
+			sb.WriteByte('\\')
+		case 'c':
+			if style == echoEscapes {
+				return sb.String(), true
+			}
+			sb.WriteString(`\c`)
+		case 'x':
+			n, value := parseDigits(s[i+1:], 16, 2)
+			if n == 0 {
+				sb.WriteString(`\x`)
+				continue
+			}
+			sb.WriteByte(byte(value))
+			i += n
+		case 'u', 'U':
+			size := 4
+			if c == 'U' {
+				size = 8
+			}
+			n, value := parseDigits(s[i+1:], 16, size)
+			if n == 0 || value > utf8.MaxRune {
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteRune(rune(value))
+			i += n
+		default:
+			if c < '0' || c > '7' {
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+				continue
+			}
+			// \0NNN for echo, \NNN for printf formats
+			start := i
+			if style == echoEscapes {
+				if c != '0' {
+					sb.WriteByte('\\')
+					sb.WriteByte(c)
+					continue
+				}
+				start++
+			}
+			n, value := parseDigits(s[start:], 8, 3)
+			sb.WriteByte(byte(value))
+			i = start + n - 1
+		}
+	}
+	return sb.String(), false
+}
+
+// parseDigits reads up to max digits in base from the start of s and
+// returns how many it read and their value
+func parseDigits(s string, base, max int) (int, uint64) {
+	n := 0
+	for n < len(s) && n < max {
+		if _, err := strconv.ParseUint(s[n:n+1], base, 8); err != nil {
+			break
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	value, _ := strconv.ParseUint(s[:n], base, 64)
+	return n, value
+}
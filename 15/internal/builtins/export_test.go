@@ -0,0 +1,8 @@
+package builtins
+
+import "minishell/internal/procfs"
+
+// SetProcFS makes ps read the processes from fs
+func (b *Builtins) SetProcFS(fs procfs.FS) {
+	b.proc = fs
+}
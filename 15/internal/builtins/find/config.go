@@ -0,0 +1,367 @@
+package find
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config for find
+type Config struct {
+	Paths    []string
+	MaxDepth int // MaxDepth is -1 when there is no limit
+	MinDepth int
+	Expr     Expr
+}
+
+// ParseConfig parses the starting points and the expression of find. The
+// expression is made of tests (-name, -iname, -path, -type, -mtime, -mmin,
+// -size), actions (-print, -print0, -exec ... ; and -exec ... {} +), the
+// options -maxdepth and -mindepth, and the operators !, -a, -o and
+// parentheses. Without an action the matching files are printed.
+func ParseConfig(args ...string) (Config, error) {
+	cfg := Config{MaxDepth: -1}
+
+	i := 0
+	for i < len(args) && !isExprStart(args[i]) {
+		cfg.Paths = append(cfg.Paths, args[i])
+		i++
+	}
+	if len(cfg.Paths) == 0 {
+		cfg.Paths = []string{"."}
+	}
+
+	p := parser{args: args[i:], cfg: &cfg}
+	if len(p.args) == 0 {
+		cfg.Expr = printExpr{sep: '\n'}
+		return cfg, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return cfg, err
+	}
+	if p.pos < len(p.args) {
+		return cfg, fmt.Errorf("unexpected '%s'", p.args[p.pos])
+	}
+	if !p.hasAction {
+		expr = andExpr{expr, printExpr{sep: '\n'}}
+	}
+	cfg.Expr = expr
+	return cfg, nil
+}
+
+// isExprStart reports whether arg starts the expression rather than
+// naming a starting point
+func isExprStart(arg string) bool {
+	return (strings.HasPrefix(arg, "-") && len(arg) > 1) || arg == "!" || arg == "(" || arg == ")"
+}
+
+// parser reads a find expression by recursive descent
+type parser struct {
+	args      []string
+	pos       int
+	cfg       *Config
+	hasAction bool
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.args) {
+		return p.args[p.pos]
+	}
+	return ""
+}
+
+// parseOr reads alternatives joined by -o
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "-o" || p.peek() == "-or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd reads terms joined by -a or simply written one after the other
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "", ")", "-o", "-or":
+			return left, nil
+		case "-a", "-and":
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+// parseUnary reads a negation, a parenthesized expression or a primary
+func (p *parser) parseUnary() (Expr, error) {
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("expected an expression")
+	case "!", "-not":
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	case "(":
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return x, nil
+	}
+	return p.parsePrimary()
+}
+
+// argPrimaries are the primaries that take an argument
+var argPrimaries = map[string]bool{
+	"-maxdepth": true, "-mindepth": true,
+	"-name": true, "-iname": true, "-path": true, "-type": true,
+	"-mtime": true, "-mmin": true, "-size": true,
+}
+
+// parsePrimary reads a test, an action or an option
+func (p *parser) parsePrimary() (Expr, error) {
+	name := p.args[p.pos]
+	p.pos++
+
+	switch name {
+	case "-print":
+		p.hasAction = true
+		return printExpr{sep: '\n'}, nil
+	case "-print0":
+		p.hasAction = true
+		return printExpr{sep: 0}, nil
+	case "-exec":
+		return p.parseExec()
+	}
+
+	if !argPrimaries[name] {
+		return nil, fmt.Errorf("unknown predicate '%s'", name)
+	}
+	if p.pos >= len(p.args) {
+		return nil, fmt.Errorf("missing argument to '%s'", name)
+	}
+	arg := p.args[p.pos]
+	p.pos++
+
+	switch name {
+	case "-maxdepth", "-mindepth":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: '%s': not a non-negative number", name, arg)
+		}
+		if name == "-maxdepth" {
+			p.cfg.MaxDepth = n
+		} else {
+			p.cfg.MinDepth = n
+		}
+		return testExpr(func(*File) bool { return true }), nil
+	case "-name", "-iname":
+		fold := name == "-iname"
+		if fold {
+			arg = strings.ToLower(arg)
+		}
+		if _, err := filepath.Match(arg, ""); err != nil {
+			return nil, fmt.Errorf("%s: '%s': %w", name, arg, err)
+		}
+		return testExpr(func(f *File) bool {
+			base := filepath.Base(f.Path)
+			if fold {
+				base = strings.ToLower(base)
+			}
+			ok, _ := filepath.Match(arg, base)
+			return ok
+		}), nil
+	case "-path":
+		return testExpr(func(f *File) bool { return matchPath(arg, f.Path) }), nil
+	case "-type":
+		return parseType(arg)
+	case "-mtime", "-mmin":
+		unit := 24 * time.Hour
+		if name == "-mmin" {
+			unit = time.Minute
+		}
+		cmp, n, err := parseNumber(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return ageExpr{cmp: cmp, n: n, unit: unit}, nil
+	case "-size":
+		return parseSize(arg)
+	}
+	return nil, fmt.Errorf("unknown predicate '%s'", name)
+}
+
+// parseExec reads the command of -exec up to ; or, after {}, up to +
+func (p *parser) parseExec() (Expr, error) {
+	start := p.pos
+	for ; p.pos < len(p.args); p.pos++ {
+		arg := p.args[p.pos]
+		batch := arg == "+" && p.pos > start && p.args[p.pos-1] == "{}"
+		if arg != ";" && !batch {
+			continue
+		}
+		command := p.args[start:p.pos]
+		p.pos++
+		if len(command) == 0 {
+			return nil, fmt.Errorf("-exec: missing command")
+		}
+		p.hasAction = true
+		if batch {
+			return &batchExpr{command: command[:len(command)-1]}, nil
+		}
+		return execExpr{command: command}, nil
+	}
+	return nil, fmt.Errorf("missing argument to '-exec'")
+}
+
+// parseType reads the letters of -type, which may be a list like f,d
+func parseType(arg string) (Expr, error) {
+	modes := map[string]fs.FileMode{
+		"f": 0,
+		"d": fs.ModeDir,
+		"l": fs.ModeSymlink,
+		"p": fs.ModeNamedPipe,
+		"s": fs.ModeSocket,
+		"c": fs.ModeDevice | fs.ModeCharDevice,
+		"b": fs.ModeDevice,
+	}
+
+	var want []fs.FileMode
+	for _, letter := range strings.Split(arg, ",") {
+		mode, ok := modes[letter]
+		if !ok {
+			return nil, fmt.Errorf("-type: unknown argument '%s'", arg)
+		}
+		want = append(want, mode)
+	}
+	return testExpr(func(f *File) bool {
+		got := f.Info.Mode().Type()
+		for _, mode := range want {
+			if got == mode {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// parseNumber reads the argument of a numeric test: +N means more than N,
+// -N less than N and N exactly N
+func parseNumber(arg string) (byte, int64, error) {
+	var cmp byte
+	digits := arg
+	if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+		cmp, digits = arg[0], arg[1:]
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 0 {
+		return 0, 0, fmt.Errorf("invalid argument '%s'", arg)
+	}
+	return cmp, n, nil
+}
+
+// sizeUnits are the suffixes of -size; the default is 512-byte blocks
+var sizeUnits = map[byte]int64{
+	'c': 1,
+	'w': 2,
+	'b': 512,
+	'k': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+}
+
+// parseSize reads the argument of -size
+func parseSize(arg string) (Expr, error) {
+	unit := int64(512)
+	if arg != "" {
+		if u, ok := sizeUnits[arg[len(arg)-1]]; ok {
+			unit = u
+			arg = arg[:len(arg)-1]
+		}
+	}
+	cmp, n, err := parseNumber(arg)
+	if err != nil {
+		return nil, fmt.Errorf("-size: %w", err)
+	}
+	return testExpr(func(f *File) bool {
+		// sizes are rounded up to whole units
+		size := (f.Info.Size() + unit - 1) / unit
+		return compare(cmp, size, n)
+	}), nil
+}
+
+// matchPath matches name against a shell pattern whose wildcards also
+// match slashes
+func matchPath(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchPath(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if name == "" {
+				return false
+			}
+			pattern, name = pattern[1:], name[1:]
+		case '[':
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 || name == "" {
+				return pattern == name
+			}
+			if ok, _ := filepath.Match(pattern[:end+2], name[:1]); !ok {
+				return false
+			}
+			pattern, name = pattern[end+2:], name[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if name == "" || name[0] != pattern[0] {
+				return false
+			}
+			pattern, name = pattern[1:], name[1:]
+		}
+	}
+	return name == ""
+}
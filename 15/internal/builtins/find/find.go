@@ -0,0 +1,245 @@
+package find
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxBatch bounds the number of files given to one -exec ... + command
+const maxBatch = 1024
+
+// Runner runs a command for -exec and returns its exit status
+type Runner func(ctx context.Context, args []string) int
+
+// Env is what find needs from the shell
+type Env struct {
+	Dir    string    // Dir is the directory relative paths start from.
+	Stdout io.Writer // Stdout receives the output of -print and -print0.
+	Run    Runner    // Run runs the commands of -exec.
+}
+
+// File is a file find visits
+type File struct {
+	Path  string // Path is the name printed, starting with a starting point.
+	Info  fs.FileInfo
+	Depth int
+}
+
+// Expr is a node of a find expression
+type Expr interface {
+	eval(w *walker, f *File) (bool, error)
+}
+
+type (
+	andExpr  struct{ left, right Expr }
+	orExpr   struct{ left, right Expr }
+	notExpr  struct{ x Expr }
+	testExpr func(f *File) bool
+
+	// printExpr writes the path followed by sep
+	printExpr struct{ sep byte }
+
+	// execExpr runs a command for every file, with {} replaced by its path
+	execExpr struct{ command []string }
+
+	// batchExpr runs a command with many paths appended at once
+	batchExpr struct {
+		command []string
+		paths   []string
+	}
+
+	// ageExpr compares the age of a file, in units, with n
+	ageExpr struct {
+		cmp  byte
+		n    int64
+		unit time.Duration
+	}
+)
+
+func (e andExpr) eval(w *walker, f *File) (bool, error) {
+	ok, err := e.left.eval(w, f)
+	if !ok || err != nil {
+		return false, err
+	}
+	return e.right.eval(w, f)
+}
+
+func (e orExpr) eval(w *walker, f *File) (bool, error) {
+	ok, err := e.left.eval(w, f)
+	if ok || err != nil {
+		return ok, err
+	}
+	return e.right.eval(w, f)
+}
+
+func (e notExpr) eval(w *walker, f *File) (bool, error) {
+	ok, err := e.x.eval(w, f)
+	return !ok, err
+}
+
+func (e testExpr) eval(w *walker, f *File) (bool, error) {
+	return e(f), nil
+}
+
+func (e printExpr) eval(w *walker, f *File) (bool, error) {
+	_, err := fmt.Fprintf(w.env.Stdout, "%s%c", f.Path, e.sep)
+	return true, err
+}
+
+func (e execExpr) eval(w *walker, f *File) (bool, error) {
+	args := make([]string, len(e.command))
+	for i, arg := range e.command {
+		args[i] = strings.ReplaceAll(arg, "{}", f.Path)
+	}
+	return w.env.Run(w.ctx, args) == 0, w.ctx.Err()
+}
+
+func (e *batchExpr) eval(w *walker, f *File) (bool, error) {
+	e.paths = append(e.paths, f.Path)
+	if len(e.paths) >= maxBatch {
+		e.flush(w)
+	}
+	return true, w.ctx.Err()
+}
+
+// flush runs the command with the paths gathered so far
+func (e *batchExpr) flush(w *walker) {
+	if len(e.paths) == 0 {
+		return
+	}
+	args := append(append([]string{}, e.command...), e.paths...)
+	e.paths = e.paths[:0]
+	if w.env.Run(w.ctx, args) != 0 {
+		w.failed = true
+	}
+}
+
+func (e ageExpr) eval(w *walker, f *File) (bool, error) {
+	age := int64(w.now.Sub(f.Info.ModTime()) / e.unit)
+	return compare(e.cmp, age, e.n), nil
+}
+
+// compare applies the comparison of a numeric test
+func compare(cmp byte, value, n int64) bool {
+	switch cmp {
+	case '+':
+		return value > n
+	case '-':
+		return value < n
+	}
+	return value == n
+}
+
+// walker holds the state of one run of find
+type walker struct {
+	ctx    context.Context
+	cfg    Config
+	env    Env
+	now    time.Time
+	errs   []error
+	failed bool // failed is set when a batched command fails
+}
+
+// Find walks the starting points of cfg and evaluates the expression on
+// every file, depth first. Symbolic links are not followed. Files that
+// cannot be read are reported in the error but do not stop the walk.
+func Find(ctx context.Context, cfg Config, env Env) error {
+	w := &walker{ctx: ctx, cfg: cfg, env: env, now: time.Now()}
+
+	for _, root := range cfg.Paths {
+		info, err := os.Lstat(w.abs(root))
+		if err != nil {
+			w.errs = append(w.errs, fmt.Errorf("'%s': %w", root, errors.Unwrap(err)))
+			continue
+		}
+		if err := w.visit(&File{Path: root, Info: info}); err != nil {
+			return err
+		}
+	}
+
+	var batches []*batchExpr
+	collectBatches(cfg.Expr, &batches)
+	for _, batch := range batches {
+		batch.flush(w)
+	}
+	if w.failed {
+		w.errs = append(w.errs, errors.New("a command run by -exec failed"))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(w.errs...)
+}
+
+// abs resolves a path against the shell's directory
+func (w *walker) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(w.env.Dir, path)
+}
+
+// visit evaluates the expression on f and descends into it if it is a
+// directory within the depth limit
+func (w *walker) visit(f *File) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	if f.Depth >= w.cfg.MinDepth {
+		if _, err := w.cfg.Expr.eval(w, f); err != nil {
+			return err
+		}
+	}
+	if !f.Info.IsDir() || (w.cfg.MaxDepth >= 0 && f.Depth >= w.cfg.MaxDepth) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(w.abs(f.Path))
+	if err != nil {
+		w.errs = append(w.errs, fmt.Errorf("'%s': %w", f.Path, errors.Unwrap(err)))
+		return nil
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		child := &File{Path: joinPath(f.Path, entry.Name()), Info: info, Depth: f.Depth + 1}
+		if err := w.visit(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinPath appends a name to a path the way find prints it, keeping a
+// leading ./
+func joinPath(dir, name string) string {
+	if strings.HasSuffix(dir, "/") {
+		return dir + name
+	}
+	return dir + "/" + name
+}
+
+// collectBatches finds the -exec ... + actions of an expression
+func collectBatches(expr Expr, batches *[]*batchExpr) {
+	switch e := expr.(type) {
+	case andExpr:
+		collectBatches(e.left, batches)
+		collectBatches(e.right, batches)
+	case orExpr:
+		collectBatches(e.left, batches)
+		collectBatches(e.right, batches)
+	case notExpr:
+		collectBatches(e.x, batches)
+	case *batchExpr:
+		*batches = append(*batches, e)
+	}
+}
@@ -0,0 +1,230 @@
+package find_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/builtins/find"
+)
+
+// makeTree creates the files of the tests; old.txt is two days old
+func makeTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":         "package a",
+		"b.txt":        strings.Repeat("x", 2000),
+		"old.txt":      "",
+		"sub/c.go":     "package c",
+		"sub/deep/d.G": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	old := time.Now().Add(-49 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "old.txt"), old, old))
+	require.NoError(t, os.Symlink("a.go", filepath.Join(dir, "link")))
+	return dir
+}
+
+func TestFind(t *testing.T) {
+	dir := makeTree(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "everything",
+			args: nil,
+			want: ".\n./a.go\n./b.txt\n./link\n./old.txt\n./sub\n./sub/c.go\n./sub/deep\n./sub/deep/d.G\n",
+		},
+		{
+			name: "name",
+			args: []string{".", "-name", "*.go"},
+			want: "./a.go\n./sub/c.go\n",
+		},
+		{
+			name: "iname",
+			args: []string{"sub", "-iname", "*.g"},
+			want: "sub/deep/d.G\n",
+		},
+		{
+			name: "path",
+			args: []string{".", "-path", "./sub*.go"},
+			want: "./sub/c.go\n",
+		},
+		{
+			name: "type",
+			args: []string{".", "-type", "d"},
+			want: ".\n./sub\n./sub/deep\n",
+		},
+		{
+			name: "type list",
+			args: []string{".", "-type", "l,d", "-maxdepth", "1"},
+			want: ".\n./link\n./sub\n",
+		},
+		{
+			name: "maxdepth",
+			args: []string{".", "-maxdepth", "1", "-type", "f"},
+			want: "./a.go\n./b.txt\n./old.txt\n",
+		},
+		{
+			name: "mindepth",
+			args: []string{".", "-mindepth", "2", "-type", "f"},
+			want: "./sub/c.go\n./sub/deep/d.G\n",
+		},
+		{
+			name: "size",
+			args: []string{".", "-type", "f", "-size", "+1k"},
+			want: "./b.txt\n",
+		},
+		{
+			name: "size in bytes",
+			args: []string{".", "-size", "9c"},
+			want: "./a.go\n./sub/c.go\n",
+		},
+		{
+			name: "mtime",
+			args: []string{".", "-type", "f", "-mtime", "+1"},
+			want: "./old.txt\n",
+		},
+		{
+			name: "recent",
+			args: []string{".", "-name", "*.txt", "-mmin", "-5"},
+			want: "./b.txt\n",
+		},
+		{
+			name: "operators",
+			args: []string{".", "(", "-name", "*.go", "-o", "-name", "*.txt", ")", "!", "-path", "*sub*"},
+			want: "./a.go\n./b.txt\n./old.txt\n",
+		},
+		{
+			name: "print0",
+			args: []string{"sub", "-name", "*.go", "-print0"},
+			want: "sub/c.go\x00",
+		},
+		{
+			name: "print only the right branch",
+			args: []string{".", "-name", "*.go", "-o", "-name", "b.txt", "-print"},
+			want: "./b.txt\n",
+		},
+		{
+			name: "absolute path",
+			args: []string{filepath.Join(dir, "sub"), "-name", "c.go"},
+			want: filepath.Join(dir, "sub", "c.go") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := find.ParseConfig(tt.args...)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			err = find.Find(context.Background(), cfg, find.Env{Dir: dir, Stdout: &out})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestFindExec(t *testing.T) {
+	dir := makeTree(t)
+
+	var calls [][]string
+	run := func(ctx context.Context, args []string) int {
+		calls = append(calls, args)
+		if strings.HasSuffix(args[len(args)-1], "a.go") {
+			return 0
+		}
+		return 1
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantCalls [][]string
+		wantOut   string
+		wantErr   bool
+	}{
+		{
+			name:      "once per file",
+			args:      []string{".", "-name", "*.go", "-exec", "check", "{}", ";", "-print"},
+			wantCalls: [][]string{{"check", "./a.go"}, {"check", "./sub/c.go"}},
+			wantOut:   "./a.go\n",
+		},
+		{
+			name:      "braces inside an argument",
+			args:      []string{"sub", "-name", "c.go", "-exec", "echo", "file={}", ";"},
+			wantCalls: [][]string{{"echo", "file=sub/c.go"}},
+		},
+		{
+			name:      "batch",
+			args:      []string{".", "-name", "*.go", "-exec", "check", "-v", "{}", "+"},
+			wantCalls: [][]string{{"check", "-v", "./a.go", "./sub/c.go"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			cfg, err := find.ParseConfig(tt.args...)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			err = find.Find(context.Background(), cfg, find.Env{Dir: dir, Stdout: &out, Run: run})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string // wantErr is a part of the message, when checked
+	}{
+		{name: "unknown predicate", args: []string{".", "-nope", "x"}, wantErr: "unknown predicate '-nope'"},
+		{name: "unknown trailing predicate", args: []string{"d", "-empty"}, wantErr: "unknown predicate '-empty'"},
+		{name: "unknown predicate after a test", args: []string{".", "-name", "a", "-newer"}, wantErr: "unknown predicate '-newer'"},
+		{name: "missing argument", args: []string{".", "-name"}, wantErr: "missing argument to '-name'"},
+		{name: "unterminated exec", args: []string{".", "-exec", "ls", "{}"}},
+		{name: "missing parenthesis", args: []string{".", "(", "-name", "a"}},
+		{name: "bad type", args: []string{".", "-type", "x"}},
+		{name: "bad size", args: []string{".", "-size", "big"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := find.ParseConfig(tt.args...)
+			assert.Error(t, err)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg, err := find.ParseConfig("missing", ".", "-name", "none")
+	require.NoError(t, err)
+	err = find.Find(context.Background(), cfg, find.Env{Dir: t.TempDir(), Stdout: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "'missing': no such file or directory")
+}
@@ -0,0 +1,238 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// pathCache remembers where external commands were found in PATH
+type pathCache struct {
+	mu    sync.Mutex
+	path  string // path is the PATH the table was filled from
+	paths map[string]string
+	hits  map[string]int
+}
+
+// LookPath returns the full path of an external command, using the hash table when possible
+func (b *Builtins) LookPath(name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return b.Abs(name), nil
+	}
+
+	b.hash.mu.Lock()
+	defer b.hash.mu.Unlock()
+
+	// like other shells, forget everything when PATH changes
+	if pathVar := b.env.Get("PATH"); pathVar != b.hash.path {
+		b.hash.path = pathVar
+		b.hash.paths = nil
+		b.hash.hits = nil
+	}
+
+	if path, ok := b.hash.paths[name]; ok {
+		b.hash.hits[name]++
+		return path, nil
+	}
+
+	path, err := b.findExecutable(name)
+	if err != nil {
+		return "", err
+	}
+	if b.hash.paths == nil {
+		b.hash.paths = map[string]string{}
+		b.hash.hits = map[string]int{}
+	}
+	b.hash.paths[name] = path
+	b.hash.hits[name] = 1
+	return path, nil
+}
+
+// hashed returns the cached path of name without touching PATH
+func (b *Builtins) hashed(name string) (string, bool) {
+	b.hash.mu.Lock()
+	defer b.hash.mu.Unlock()
+	path, ok := b.hash.paths[name]
+	return path, ok
+}
+
+// Hash prints, resets or fills the table of remembered command paths; -d
+// forgets the paths of names and -t prints them
+func (b *Builtins) Hash(args ...string) (string, error) {
+	if len(args) == 0 {
+		b.hash.mu.Lock()
+		defer b.hash.mu.Unlock()
+
+		if len(b.hash.paths) == 0 {
+			return "hash: hash table empty", nil
+		}
+		keys := make([]string, 0, len(b.hash.paths))
+		for name := range b.hash.paths {
+			keys = append(keys, name)
+		}
+		slices.Sort(keys)
+
+		output := "hits   command"
+		for _, name := range keys {
+			output += "\n" + fmt.Sprintf("%4d   %s", b.hash.hits[name], b.hash.paths[name])
+		}
+		return output, nil
+	}
+
+	switch args[0] {
+	case "-r":
+		b.hash.mu.Lock()
+		b.hash.paths = nil
+		b.hash.hits = nil
+		b.hash.mu.Unlock()
+		args = args[1:]
+	case "-d":
+		return "", b.forget(args[1:])
+	case "-t":
+		return b.remembered(args[1:])
+	}
+
+	var errs []error
+	for _, name := range args {
+		// like bash, a path is run as it is and never remembered
+		if b.IsBuiltin(name) || strings.Contains(name, "/") {
+			continue
+		}
+		if _, err := b.LookPath(name); err != nil {
+			errs = append(errs, fmt.Errorf("hash: %s: not found", name))
+			continue
+		}
+		b.hash.mu.Lock()
+		b.hash.hits[name] = 0
+		b.hash.mu.Unlock()
+	}
+	return "", errors.Join(errs...)
+}
+
+// forget removes names from the table
+func (b *Builtins) forget(names []string) error {
+	b.hash.mu.Lock()
+	defer b.hash.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := b.hash.paths[name]; !ok {
+			errs = append(errs, fmt.Errorf("hash: %s: not found", name))
+			continue
+		}
+		delete(b.hash.paths, name)
+		delete(b.hash.hits, name)
+	}
+	return errors.Join(errs...)
+}
+
+// remembered returns the paths of names in the table, each after its name
+// when there are several
+func (b *Builtins) remembered(names []string) (string, error) {
+	var lines []string
+	var errs []error
+	for _, name := range names {
+		path, ok := b.hashed(name)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("hash: %s: not found", name))
+		case len(names) > 1:
+			lines = append(lines, name+"\t"+path)
+		default:
+			lines = append(lines, path)
+		}
+	}
+	return strings.Join(lines, "\n"), errors.Join(errs...)
+}
+
+// Type describes how each name would be interpreted as a command
+func (b *Builtins) Type(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	var lines []string
+	var errs []error
+	for _, name := range args {
+		if b.IsBuiltin(name) {
+			lines = append(lines, name+" is a shell builtin")
+			continue
+		}
+		if path, ok := b.hashed(name); ok {
+			lines = append(lines, fmt.Sprintf("%s is hashed (%s)", name, path))
+			continue
+		}
+		path, err := b.findExecutable(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("type: %s: not found", name))
+			continue
+		}
+		lines = append(lines, name+" is "+path)
+	}
+	return strings.Join(lines, "\n"), errors.Join(errs...)
+}
+
+// Which prints the path of the external commands
+func (b *Builtins) Which(args ...string) (string, error) {
+	var lines []string
+	var errs []error
+	for _, name := range args {
+		path, err := b.LookPath(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("which: no %s in PATH", name))
+			continue
+		}
+		lines = append(lines, path)
+	}
+	return strings.Join(lines, "\n"), errors.Join(errs...)
+}
+
+// Command handles the -v and -V forms of command; running a command
+// without its builtin is done by the shell
+func (b *Builtins) Command(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	switch args[0] {
+	case "-V":
+		return b.Type(args[1:]...)
+	case "-v":
+		var lines []string
+		var errs []error
+		for _, name := range args[1:] {
+			if b.IsBuiltin(name) {
+				lines = append(lines, name)
+				continue
+			}
+			path, err := b.LookPath(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("command: %s: not found", name))
+				continue
+			}
+			lines = append(lines, path)
+		}
+		return strings.Join(lines, "\n"), errors.Join(errs...)
+	default:
+		return "", fmt.Errorf("usage: command [-v|-V] name [arg ...]")
+	}
+}
+
+// findExecutable searches PATH of the shell variables for an executable file
+func (b *Builtins) findExecutable(name string) (string, error) {
+	for _, dir := range filepath.SplitList(b.env.Get("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		path := filepath.Join(b.Abs(dir), name)
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0 {
+			return path, nil
+		}
+	}
+	return "", ErrCmdNotFound
+}
@@ -0,0 +1,204 @@
+package builtins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// countOptions are the options head and tail share
+type countOptions struct {
+	count   int64
+	bytes   bool // bytes counts bytes instead of lines
+	sign    byte // sign is the + or - written before the count, or 0
+	quiet   bool
+	verbose bool
+	follow  bool
+}
+
+// parseCountOptions reads -n N, -c N, the old form -N, -q and -v, and -f
+// when follow is allowed; it returns the remaining arguments
+func parseCountOptions(cmd string, args []string, follow bool) (countOptions, []string, error) {
+	opts := countOptions{count: 10}
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		if opt[1] >= '0' && opt[1] <= '9' {
+			if err := opts.setCount(cmd, opt[1:]); err != nil {
+				return opts, nil, err
+			}
+			continue
+		}
+
+		for i := 1; i < len(opt); i++ {
+			switch opt[i] {
+			case 'q':
+				opts.quiet, opts.verbose = true, false
+				continue
+			case 'v':
+				opts.verbose, opts.quiet = true, false
+				continue
+			case 'f':
+				if follow {
+					opts.follow = true
+					continue
+				}
+			case 'n', 'c':
+				opts.bytes = opt[i] == 'c'
+				value := opt[i+1:]
+				if value == "" {
+					if len(args) == 0 {
+						return opts, nil, usageError(cmd, "-%c: option requires an argument", opt[i])
+					}
+					value, args = args[0], args[1:]
+				}
+				if err := opts.setCount(cmd, value); err != nil {
+					return opts, nil, err
+				}
+				i = len(opt)
+				continue
+			}
+			return opts, nil, usageError(cmd, "-%c: invalid option", opt[i])
+		}
+	}
+	return opts, args, nil
+}
+
+// setCount parses a count with an optional sign
+func (o *countOptions) setCount(cmd, value string) error {
+	o.sign = 0
+	digits := value
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		o.sign, digits = value[0], value[1:]
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 0 {
+		unit := "lines"
+		if o.bytes {
+			unit = "bytes"
+		}
+		return usageError(cmd, "invalid number of %s: '%s'", unit, value)
+	}
+	o.count = n
+	return nil
+}
+
+// headerWriter prints the ==> name <== headers between the inputs of head
+// and tail
+type headerWriter struct {
+	out     io.Writer
+	enabled bool
+	printed bool
+}
+
+func (h *headerWriter) header(name string) {
+	if !h.enabled {
+		return
+	}
+	if h.printed {
+		io.WriteString(h.out, "\n")
+	}
+	fmt.Fprintf(h.out, "==> %s <==\n", displayName(name))
+	h.printed = true
+}
+
+// Head prints the first 10 lines of the files, or of stdin. -n N and -c N
+// print N lines or bytes instead, and with -N everything but the last N.
+// Headers name the files when there are several, unless -q; -v always
+// prints them.
+func (b *Builtins) Head(ctx context.Context, stdio IO, args ...string) error {
+	opts, names, err := parseCountOptions("head", args, false)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(stdio.Stdout)
+	headers := headerWriter{out: out, enabled: opts.verbose || (len(names) > 1 && !opts.quiet)}
+	err = b.eachInput(ctx, stdio.Stdin, "head", names, func(name string, r io.Reader) error {
+		headers.header(name)
+		switch {
+		case opts.sign == '-' && opts.bytes:
+			return copyAllButLastBytes(out, r, opts.count)
+		case opts.sign == '-':
+			return copyAllButLastLines(out, r, opts.count)
+		case opts.bytes:
+			_, err := io.CopyN(out, r, opts.count)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		return copyFirstLines(out, r, opts.count)
+	})
+	if flushErr := out.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// errEnough ends reading once a builtin has what it needs
+var errEnough = errors.New("enough input")
+
+// copyFirstLines copies the first n lines of r, reading no further
+func copyFirstLines(w io.Writer, r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	err := eachLine(r, func(line []byte) error {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		if n--; n == 0 {
+			return errEnough
+		}
+		return nil
+	})
+	if errors.Is(err, errEnough) {
+		return nil
+	}
+	return err
+}
+
+// copyAllButLastLines copies r except for its last n lines
+func copyAllButLastLines(w io.Writer, r io.Reader, n int64) error {
+	var held [][]byte
+	return eachLine(r, func(line []byte) error {
+		held = append(held, line)
+		if int64(len(held)) <= n {
+			return nil
+		}
+		_, err := w.Write(held[0])
+		held = held[1:]
+		return err
+	})
+}
+
+// copyAllButLastBytes copies r except for its last n bytes
+func copyAllButLastBytes(w io.Writer, r io.Reader, n int64) error {
+	var held []byte
+	buf := make([]byte, 32*1024)
+	for {
+		k, err := r.Read(buf)
+		held = append(held, buf[:k]...)
+		if extra := int64(len(held)) - n; extra > 0 {
+			if _, werr := w.Write(held[:extra]); werr != nil {
+				return werr
+			}
+			held = append(held[:0], held[extra:]...)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"minishell/internal/signals"
+)
+
+const killUsage = "usage: kill [-s SIGNAL | -SIGNAL] pid | %job ... or kill -l [signal]"
+
+// Kill sends a signal to processes, process groups (negative pids) and
+// jobs (%N). Every target that fails gets its own error.
+func (b *Builtins) Kill(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New(killUsage)
+	}
+
+	sig := syscall.SIGTERM
+	targets := args
+
+	switch arg := args[0]; {
+	case arg == "-l" || arg == "-L":
+		return listSignals(args[1:])
+	case arg == "-s" || arg == "-n":
+		if len(args) < 2 {
+			return "", fmt.Errorf("kill: %s: option requires an argument", arg)
+		}
+		parsed, err := signals.Parse(args[1])
+		if err != nil {
+			return "", fmt.Errorf("kill: %w", err)
+		}
+		sig, targets = parsed, args[2:]
+	case arg == "--":
+		targets = args[1:]
+	case strings.HasPrefix(arg, "-") && len(arg) > 1:
+		parsed, err := signals.Parse(arg[1:])
+		if err != nil {
+			return "", fmt.Errorf("kill: %w", err)
+		}
+		sig, targets = parsed, args[1:]
+	}
+	if len(targets) > 0 && targets[0] == "--" {
+		targets = targets[1:]
+	}
+	if len(targets) == 0 {
+		return "", errors.New(killUsage)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		pids, err := b.resolveKillTarget(target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, pid := range pids {
+			if err := syscall.Kill(pid, sig); err != nil {
+				errs = append(errs, fmt.Errorf("kill: (%d) - %w", pid, err))
+			}
+		}
+	}
+	return "", errors.Join(errs...)
+}
+
+// resolveKillTarget turns a pid, a negative process group id or a job spec
+// into the pids to signal
+func (b *Builtins) resolveKillTarget(target string) ([]int, error) {
+	if strings.HasPrefix(target, "%") {
+		id, err := b.parseJobSpec(target)
+		if err != nil {
+			return nil, err
+		}
+		procs := b.jobs.Job(id)
+		if len(procs) == 0 {
+			return nil, fmt.Errorf("kill: %s: no such job", target)
+		}
+		pids := make([]int, len(procs))
+		for i, p := range procs {
+			pids[i] = p.PID
+		}
+		return pids, nil
+	}
+
+	pid, err := strconv.Atoi(target)
+	if err != nil {
+		return nil, fmt.Errorf("kill: %s: arguments must be process or job IDs", target)
+	}
+	return []int{pid}, nil
+}
+
+// parseJobSpec parses %N, and %%/%+ for the most recent job
+func (b *Builtins) parseJobSpec(spec string) (int, error) {
+	switch spec {
+	case "%", "%%", "%+":
+		current := b.jobs.NextJob() - 1
+		if current == 0 {
+			return 0, fmt.Errorf("kill: %s: no current job", spec)
+		}
+		return current, nil
+	}
+	id, err := strconv.Atoi(spec[1:])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("kill: %s: no such job", spec)
+	}
+	return id, nil
+}
+
+// listSignals implements kill -l: with no arguments it prints the table of
+// signals, otherwise it converts numbers to names and names to numbers
+func listSignals(args []string) (string, error) {
+	if len(args) == 0 {
+		var sb strings.Builder
+		for i, sig := range signals.List() {
+			switch {
+			case i == 0:
+			case i%5 == 0:
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte('\t')
+			}
+			fmt.Fprintf(&sb, "%2d) SIG%s", int(sig), signals.Name(sig))
+		}
+		return sb.String(), nil
+	}
+
+	var lines []string
+	var errs []error
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			// exit statuses of killed processes are 128+signal
+			if n > 128 {
+				n -= 128
+			}
+			sig, err := signals.Parse(strconv.Itoa(n))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("kill: %w", err))
+				continue
+			}
+			lines = append(lines, signals.Name(sig))
+			continue
+		}
+		sig, err := signals.Parse(arg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("kill: %w", err))
+			continue
+		}
+		lines = append(lines, strconv.Itoa(int(sig)))
+	}
+	return strings.Join(lines, "\n"), errors.Join(errs...)
+}
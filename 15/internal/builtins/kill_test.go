@@ -0,0 +1,207 @@
+package builtins_test
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/builtins"
+	"minishell/internal/env"
+	"minishell/internal/models"
+)
+
+// startSleep starts a process to be signalled, in its own process group
+// when group is set
+func startSleep(t *testing.T, group bool) *exec.Cmd {
+	t.Helper()
+
+	cmd := exec.Command("sleep", "10")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: group}
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd
+}
+
+// waitSignal returns the signal that stopped cmd
+func waitSignal(t *testing.T, cmd *exec.Cmd) syscall.Signal {
+	t.Helper()
+
+	cmd.Wait()
+	ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	require.True(t, ok)
+	require.True(t, ws.Signaled(), "process exited with %v", cmd.ProcessState)
+	return ws.Signal()
+}
+
+func TestKillList(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "number", args: []string{"-l", "9"}, want: "KILL"},
+		{name: "exit status", args: []string{"-l", "143"}, want: "TERM"},
+		{name: "name", args: []string{"-l", "SIGINT"}, want: "2"},
+		{name: "several", args: []string{"-L", "1", "quit"}, want: "HUP\n3"},
+		{
+			name:    "invalid among valid",
+			args:    []string{"-l", "15", "BOGUS"},
+			want:    "TERM",
+			wantErr: "kill: invalid signal specification: BOGUS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := builtins.New(env.New(nil), t.TempDir())
+
+			out, err := b.Kill(tt.args...)
+			assert.Equal(t, tt.want, out)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKillListTable(t *testing.T) {
+	b := builtins.New(env.New(nil), t.TempDir())
+
+	out, err := b.Kill("-l")
+	require.NoError(t, err)
+	lines := strings.Split(out, "\n")
+	assert.Equal(t, " 1) SIGHUP\t 2) SIGINT\t 3) SIGQUIT\t 4) SIGILL\t 5) SIGTRAP", lines[0])
+	assert.Contains(t, out, " 9) SIGKILL")
+	assert.Contains(t, out, "15) SIGTERM")
+}
+
+func TestKill(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    func(pid int) []string
+		group   bool
+		wantSig syscall.Signal
+	}{
+		{
+			name:    "default signal",
+			args:    func(pid int) []string { return []string{strconv.Itoa(pid)} },
+			wantSig: syscall.SIGTERM,
+		},
+		{
+			name:    "signal name",
+			args:    func(pid int) []string { return []string{"-s", "INT", strconv.Itoa(pid)} },
+			wantSig: syscall.SIGINT,
+		},
+		{
+			name:    "signal number",
+			args:    func(pid int) []string { return []string{"-9", strconv.Itoa(pid)} },
+			wantSig: syscall.SIGKILL,
+		},
+		{
+			name:    "signal name as option",
+			args:    func(pid int) []string { return []string{"-SIGUSR1", strconv.Itoa(pid)} },
+			wantSig: syscall.SIGUSR1,
+		},
+		{
+			name:    "process group",
+			args:    func(pid int) []string { return []string{"--", strconv.Itoa(-pid)} },
+			group:   true,
+			wantSig: syscall.SIGTERM,
+		},
+		{
+			name:    "process group with signal",
+			args:    func(pid int) []string { return []string{"-s", "HUP", "--", strconv.Itoa(-pid)} },
+			group:   true,
+			wantSig: syscall.SIGHUP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := builtins.New(env.New(nil), t.TempDir())
+			cmd := startSleep(t, tt.group)
+
+			out, err := b.Kill(tt.args(cmd.Process.Pid)...)
+			require.NoError(t, err)
+			assert.Empty(t, out)
+			assert.Equal(t, tt.wantSig, waitSignal(t, cmd))
+		})
+	}
+}
+
+func TestKillJob(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "number", spec: "%1"},
+		{name: "current", spec: "%%"},
+		{name: "plus", spec: "%+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := builtins.New(env.New(nil), t.TempDir())
+			first, second := startSleep(t, false), startSleep(t, false)
+			job := b.Jobs().NextJob()
+			b.Jobs().Add(models.Process{PID: first.Process.Pid, Job: job, Cmd: "sleep"})
+			b.Jobs().Add(models.Process{PID: second.Process.Pid, Job: job, Cmd: "sleep"})
+
+			_, err := b.Kill("-KILL", tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, syscall.SIGKILL, waitSignal(t, first))
+			assert.Equal(t, syscall.SIGKILL, waitSignal(t, second))
+		})
+	}
+}
+
+func TestKillErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no arguments", args: nil, wantErr: "usage: kill [-s SIGNAL | -SIGNAL] pid | %job ... or kill -l [signal]"},
+		{name: "no targets", args: []string{"-9"}, wantErr: "usage: kill [-s SIGNAL | -SIGNAL] pid | %job ... or kill -l [signal]"},
+		{name: "missing signal", args: []string{"-s"}, wantErr: "kill: -s: option requires an argument"},
+		{name: "invalid signal", args: []string{"-s", "BOGUS", "1"}, wantErr: "kill: invalid signal specification: BOGUS"},
+		{name: "no current job", args: []string{"%%"}, wantErr: "kill: %%: no current job"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := builtins.New(env.New(nil), t.TempDir())
+
+			_, err := b.Kill(tt.args...)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, 1, builtins.ExitStatus(err))
+		})
+	}
+}
+
+func TestKillPartialFailure(t *testing.T) {
+	b := builtins.New(env.New(nil), t.TempDir())
+	first, last := startSleep(t, false), startSleep(t, false)
+
+	// pids are below 2^22 on Linux and 99999 on macOS
+	_, err := b.Kill(strconv.Itoa(first.Process.Pid), "bogus", "%7", "99999999", strconv.Itoa(last.Process.Pid))
+	assert.EqualError(t, err, "kill: bogus: arguments must be process or job IDs\n"+
+		"kill: %7: no such job\n"+
+		"kill: (99999999) - no such process")
+	assert.Equal(t, 1, builtins.ExitStatus(err))
+
+	// the targets that could be signalled were
+	assert.Equal(t, syscall.SIGTERM, waitSignal(t, first))
+	assert.Equal(t, syscall.SIGTERM, waitSignal(t, last))
+}
@@ -0,0 +1,252 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Printf formats its arguments like printf(1): %s %b %q %c %d %i %u %x %X
+// %o %f %e %g and %% with flags, width and precision, where * takes them
+// from the arguments. The format is reused while arguments remain.
+// Arguments that are not numbers are printed as 0 and reported.
+func (b *Builtins) Printf(args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return "", &StatusError{Status: 2, Err: errors.New("printf: usage: printf format [arguments]")}
+	}
+
+	p := printer{format: args[0], args: args[1:]}
+	for {
+		used := p.pos
+		if err := p.run(); err != nil {
+			return p.out.String(), err
+		}
+		if p.stop || p.pos == used || p.pos >= len(p.args) {
+			break
+		}
+	}
+
+	if len(p.errs) > 0 {
+		return p.out.String(), &StatusError{Status: 1, Err: errors.Join(p.errs...)}
+	}
+	return p.out.String(), nil
+}
+
+// printer holds the state of one printf invocation
+type printer struct {
+	format string
+	args   []string
+	pos    int // pos is the index of the next argument
+	out    strings.Builder
+	errs   []error
+	stop   bool // stop is set by \c in a %b argument
+}
+
+// run goes once through the format
+func (p *printer) run() error {
+	f := p.format
+	for i := 0; i < len(f) && !p.stop; i++ {
+		switch f[i] {
+		case '\\':
+			n := escapeLength(f[i:])
+			text, _ := unescape(f[i:i+n], printfEscapes)
+			p.out.WriteString(text)
+			i += n - 1
+		case '%':
+			n, err := p.directive(f[i+1:])
+			if err != nil {
+				return err
+			}
+			i += n
+		default:
+			p.out.WriteByte(f[i])
+		}
+	}
+	return nil
+}
+
+// escapeLength returns how many bytes the escape at the start of s uses
+func escapeLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch c := s[1]; {
+	case c >= '0' && c <= '7':
+		n, _ := parseDigits(s[1:], 8, 3)
+		return 1 + n
+	case c == 'x':
+		n, _ := parseDigits(s[2:], 16, 2)
+		return 2 + n
+	case c == 'u':
+		n, _ := parseDigits(s[2:], 16, 4)
+		return 2 + n
+	case c == 'U':
+		n, _ := parseDigits(s[2:], 16, 8)
+		return 2 + n
+	}
+	return 2
+}
+
+// directive formats one conversion; s starts after the % and the number of
+// bytes used is returned
+func (p *printer) directive(s string) (int, error) {
+	i := 0
+	for i < len(s) && strings.IndexByte("-+ #0", s[i]) >= 0 {
+		i++
+	}
+	flags := s[:i]
+
+	width, n := p.number(s[i:])
+	i += n
+	precision := ""
+	if i < len(s) && s[i] == '.' {
+		i++
+		prec, n := p.number(s[i:])
+		i += n
+		if prec == "" {
+			prec = "0"
+		}
+		precision = "." + prec
+	}
+
+	if i >= len(s) {
+		return i, &StatusError{Status: 1, Err: errors.New("printf: missing format character")}
+	}
+	verb := s[i]
+	spec := "%" + flags + width + precision
+
+	switch verb {
+	case '%':
+		p.out.WriteByte('%')
+	case 's':
+		fmt.Fprintf(&p.out, spec+"s", p.next())
+	case 'b':
+		text, stop := unescape(p.next(), echoEscapes)
+		fmt.Fprintf(&p.out, spec+"s", text)
+		p.stop = stop
+	case 'q':
+		fmt.Fprintf(&p.out, spec+"s", shellQuote(p.next()))
+	case 'c':
+		arg := p.next()
+		if arg != "" {
+			r, _ := utf8.DecodeRuneInString(arg)
+			arg = string(r)
+		}
+		fmt.Fprintf(&p.out, spec+"s", arg)
+	case 'd', 'i':
+		fmt.Fprintf(&p.out, spec+"d", p.integer())
+	case 'u', 'x', 'X', 'o':
+		v := verb
+		if v == 'u' {
+			v = 'd'
+		}
+		fmt.Fprintf(&p.out, spec+string(v), uint64(p.integer()))
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		if precision == "" {
+			spec += ".6"
+		}
+		fmt.Fprintf(&p.out, spec+string(verb), p.float())
+	default:
+		return i + 1, &StatusError{Status: 1, Err: fmt.Errorf("printf: %%%c: invalid format character", verb)}
+	}
+	return i + 1, nil
+}
+
+// number reads a width or precision: digits, or * for the next argument
+func (p *printer) number(s string) (string, int) {
+	if strings.HasPrefix(s, "*") {
+		return strconv.FormatInt(p.integer(), 10), 1
+	}
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return s[:n], n
+}
+
+// next returns the next argument, or "" when they are used up
+func (p *printer) next() string {
+	if p.pos >= len(p.args) {
+		return ""
+	}
+	p.pos++
+	return p.args[p.pos-1]
+}
+
+// integer converts the next argument; 'c and "c give the code of c
+func (p *printer) integer() int64 {
+	arg := p.next()
+	if arg == "" {
+		return 0
+	}
+	if arg[0] == '\'' || arg[0] == '"' {
+		r, _ := utf8.DecodeRuneInString(arg[1:])
+		return int64(r)
+	}
+
+	s := strings.TrimSpace(arg)
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		u, uerr := strconv.ParseUint(s, 0, 64)
+		if uerr != nil {
+			p.errs = append(p.errs, fmt.Errorf("printf: %s: invalid number", arg))
+			return 0
+		}
+		n = int64(u)
+	}
+	return n
+}
+
+// float converts the next argument
+func (p *printer) float() float64 {
+	arg := p.next()
+	if arg == "" {
+		return 0
+	}
+	if arg[0] == '\'' || arg[0] == '"' {
+		r, _ := utf8.DecodeRuneInString(arg[1:])
+		return float64(r)
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("printf: %s: invalid number", arg))
+		return 0
+	}
+	return f
+}
+
+// shellQuote quotes s so that the shell reads it back as one word, the way
+// bash's printf %q does
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+
+	printable := true
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			printable = false
+			break
+		}
+	}
+	if !printable {
+		q := strconv.Quote(s)
+		q = strings.ReplaceAll(q[1:len(q)-1], `\"`, `"`)
+		return "$'" + strings.ReplaceAll(q, "'", `\'`) + "'"
+	}
+
+	var sb strings.Builder
+	for _, r := range s {
+		if !strings.ContainsRune("_-./,:=@%+", r) && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r < utf8.RuneSelf {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,330 @@
+package builtins
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"minishell/internal/procfs"
+)
+
+// psColumn describes a column that can be selected with ps -o
+type psColumn struct {
+	header  string
+	numeric bool // numeric columns are right-aligned
+	value   func(p procfs.Proc) string
+	compare func(a, b procfs.Proc) int
+}
+
+var psColumns = map[string]psColumn{
+	"pid": {
+		header: "PID", numeric: true,
+		value:   func(p procfs.Proc) string { return strconv.Itoa(p.PID) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.PID, b.PID) },
+	},
+	"ppid": {
+		header: "PPID", numeric: true,
+		value:   func(p procfs.Proc) string { return strconv.Itoa(p.PPID) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.PPID, b.PPID) },
+	},
+	"pgid": {
+		header: "PGID", numeric: true,
+		value:   func(p procfs.Proc) string { return strconv.Itoa(p.PGID) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.PGID, b.PGID) },
+	},
+	"stat": {
+		header:  "S",
+		value:   func(p procfs.Proc) string { return p.State },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.State, b.State) },
+	},
+	"uid": {
+		header: "UID", numeric: true,
+		value:   func(p procfs.Proc) string { return strconv.Itoa(p.UID) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.UID, b.UID) },
+	},
+	"user": {
+		header:  "USER",
+		value:   func(p procfs.Proc) string { return userName(p.UID) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(userName(a.UID), userName(b.UID)) },
+	},
+	"time": {
+		header: "TIME", numeric: true,
+		value:   func(p procfs.Proc) string { return formatCPUTime(p.CPUTime) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.CPUTime, b.CPUTime) },
+	},
+	"rss": {
+		header: "RSS", numeric: true,
+		value:   func(p procfs.Proc) string { return strconv.FormatInt(p.RSS/1024, 10) },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.RSS, b.RSS) },
+	},
+	"comm": {
+		header:  "COMMAND",
+		value:   func(p procfs.Proc) string { return p.Comm },
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(a.Comm, b.Comm) },
+	},
+	"args": {
+		header:  "CMD",
+		value:   procArgs,
+		compare: func(a, b procfs.Proc) int { return cmp.Compare(procArgs(a), procArgs(b)) },
+	},
+}
+
+// psAliases maps alternative procps column names to the ones in psColumns
+var psAliases = map[string]string{
+	"s":       "stat",
+	"state":   "stat",
+	"cputime": "time",
+	"rssize":  "rss",
+	"ucmd":    "comm",
+	"cmd":     "args",
+	"command": "args",
+}
+
+var (
+	psDefaultFormat = []string{"pid", "stat", "time", "comm"}
+	psFullFormat    = []string{"user", "pid", "ppid", "stat", "rss", "time", "args"}
+)
+
+// psOptions holds the parsed arguments of ps
+type psOptions struct {
+	all     bool
+	format  []string
+	sortBy  []string
+	full    bool
+	columns []psColumn
+}
+
+// Ps lists processes. Without -e only the shell and the processes it
+// started are shown.
+func (b *Builtins) Ps(args ...string) (string, error) {
+	opts, err := parsePsArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	procs, err := b.psProcesses(opts.all)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sortProcs(procs, opts.sortBy); err != nil {
+		return "", err
+	}
+
+	rows := make([][]string, 0, len(procs)+1)
+	header := make([]string, len(opts.columns))
+	for i, col := range opts.columns {
+		header[i] = col.header
+	}
+	rows = append(rows, header)
+	for _, p := range procs {
+		row := make([]string, len(opts.columns))
+		for i, col := range opts.columns {
+			row[i] = col.value(p)
+		}
+		rows = append(rows, row)
+	}
+
+	return formatTable(rows, opts.columns), nil
+}
+
+func parsePsArgs(args []string) (psOptions, error) {
+	opts := psOptions{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-e" || arg == "-A":
+			opts.all = true
+		case arg == "-f":
+			opts.full = true
+		case arg == "-ef" || arg == "-fe":
+			opts.all = true
+			opts.full = true
+		case arg == "-o":
+			i++
+			if i >= len(args) {
+				return opts, errors.New("ps: option -o requires an argument")
+			}
+			opts.format = append(opts.format, strings.Split(args[i], ",")...)
+		case strings.HasPrefix(arg, "-o"):
+			opts.format = append(opts.format, strings.Split(arg[2:], ",")...)
+		case arg == "--sort":
+			i++
+			if i >= len(args) {
+				return opts, errors.New("ps: option --sort requires an argument")
+			}
+			opts.sortBy = append(opts.sortBy, strings.Split(args[i], ",")...)
+		case strings.HasPrefix(arg, "--sort="):
+			opts.sortBy = append(opts.sortBy, strings.Split(strings.TrimPrefix(arg, "--sort="), ",")...)
+		default:
+			return opts, fmt.Errorf("ps: unknown option: %s", arg)
+		}
+	}
+
+	format := opts.format
+	if len(format) == 0 {
+		format = psDefaultFormat
+		if opts.full {
+			format = psFullFormat
+		}
+	}
+	for _, name := range format {
+		col, err := lookupPsColumn(name)
+		if err != nil {
+			return opts, err
+		}
+		opts.columns = append(opts.columns, col)
+	}
+
+	return opts, nil
+}
+
+func lookupPsColumn(name string) (psColumn, error) {
+	name = strings.ToLower(name)
+	if alias, ok := psAliases[name]; ok {
+		name = alias
+	}
+	col, ok := psColumns[name]
+	if !ok {
+		return psColumn{}, fmt.Errorf("ps: unknown column: %s", name)
+	}
+	return col, nil
+}
+
+// psProcesses returns either every process in /proc or the shell itself
+// with the processes registered in its job table
+func (b *Builtins) psProcesses(all bool) ([]procfs.Proc, error) {
+	if all {
+		procs, err := b.proc.All()
+		if err != nil {
+			return nil, fmt.Errorf("ps: %w", err)
+		}
+		return procs, nil
+	}
+
+	self := procfs.Proc{PID: os.Getpid(), PPID: os.Getppid(), Comm: "minishell"}
+	if p, err := b.proc.Read(self.PID); err == nil {
+		self = p
+	}
+	procs := []procfs.Proc{self}
+
+	for _, child := range b.jobs.Processes() {
+		p, err := b.proc.Read(child.PID)
+		if err != nil {
+			// no /proc or the process is already gone: show what the shell knows
+			p = procfs.Proc{PID: child.PID, PPID: self.PID, Comm: child.Cmd, Cmdline: []string{child.Cmd}}
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// sortProcs sorts by the given keys, each optionally prefixed with + or -
+func sortProcs(procs []procfs.Proc, keys []string) error {
+	type sortKey struct {
+		compare func(a, b procfs.Proc) int
+		desc    bool
+	}
+
+	sortKeys := []sortKey{{compare: psColumns["pid"].compare}}
+	if len(keys) > 0 {
+		sortKeys = sortKeys[:0]
+	}
+	for _, key := range keys {
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimLeft(key, "+-")
+		col, err := lookupPsColumn(key)
+		if err != nil {
+			return err
+		}
+		sortKeys = append(sortKeys, sortKey{compare: col.compare, desc: desc})
+	}
+
+	slices.SortStableFunc(procs, func(a, b procfs.Proc) int {
+		for _, k := range sortKeys {
+			c := k.compare(a, b)
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// formatTable pads the columns; the last one is left as is
+func formatTable(rows [][]string, columns []psColumn) string {
+	widths := make([]int, len(columns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			switch {
+			case columns[i].numeric:
+				fmt.Fprintf(&sb, "%*s", widths[i], cell)
+			case i == len(row)-1:
+				sb.WriteString(cell)
+			default:
+				fmt.Fprintf(&sb, "%-*s", widths[i], cell)
+			}
+		}
+		lines[r] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatCPUTime formats a duration as [DD-]HH:MM:SS like procps does
+func formatCPUTime(d time.Duration) string {
+	secs := int64(d / time.Second)
+	days := secs / 86400
+	secs %= 86400
+	s := fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	if days > 0 {
+		s = fmt.Sprintf("%d-%s", days, s)
+	}
+	return s
+}
+
+// procArgs returns the command line, or [comm] for kernel threads
+func procArgs(p procfs.Proc) string {
+	if len(p.Cmdline) == 0 {
+		return "[" + p.Comm + "]"
+	}
+	return strings.Join(p.Cmdline, " ")
+}
+
+// userNames caches uid lookups, which read /etc/passwd every time
+var userNames sync.Map
+
+// userName resolves a uid, falling back to the number
+func userName(uid int) string {
+	if name, ok := userNames.Load(uid); ok {
+		return name.(string)
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	userNames.Store(uid, name)
+	return name
+}
@@ -0,0 +1,182 @@
+//go:build linux
+
+package builtins_test
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/builtins"
+	"minishell/internal/env"
+	"minishell/internal/models"
+	"minishell/internal/procfs"
+)
+
+// fakeProc is a process written to a fake proc filesystem
+type fakeProc struct {
+	pid, ppid   int
+	state, comm string
+	ticks       int // utime, stime is 0
+	rssPages    int
+	cmdline     []string
+}
+
+// newPsBuiltins returns builtins whose ps reads init, a kernel thread and
+// a sleep from a temporary directory
+func newPsBuiltins(t *testing.T) *builtins.Builtins {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, p := range []fakeProc{
+		{pid: 1, ppid: 0, state: "S", comm: "init", ticks: 150, rssPages: 10, cmdline: []string{"/sbin/init", "splash"}},
+		{pid: 2, ppid: 0, state: "I", comm: "kthreadd"},
+		{pid: 4242, ppid: 1, state: "R", comm: "sleep", ticks: 360000, rssPages: 20, cmdline: []string{"sleep", "60"}},
+	} {
+		dir := filepath.Join(root, strconv.Itoa(p.pid))
+		require.NoError(t, os.Mkdir(dir, 0o755))
+
+		// fields 3 to 24 of proc(5): state, ppid, pgid, ..., utime, stime, ..., rss
+		fields := make([]string, 22)
+		for i := range fields {
+			fields[i] = "0"
+		}
+		fields[0] = p.state
+		fields[1] = strconv.Itoa(p.ppid)
+		fields[2] = strconv.Itoa(p.pid)
+		fields[11] = strconv.Itoa(p.ticks)
+		fields[21] = strconv.Itoa(p.rssPages)
+		stat := fmt.Sprintf("%d (%s) %s\n", p.pid, p.comm, strings.Join(fields, " "))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644))
+
+		var cmdline string
+		for _, arg := range p.cmdline {
+			cmdline += arg + "\x00"
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644))
+	}
+
+	b := builtins.New(env.New(nil), t.TempDir())
+	b.SetProcFS(procfs.New(root))
+	return b
+}
+
+func TestPs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "all processes",
+			args: []string{"-e"},
+			want: " PID S     TIME COMMAND\n" +
+				"   1 S 00:00:01 init\n" +
+				"   2 I 00:00:00 kthreadd\n" +
+				"4242 R 01:00:00 sleep",
+		},
+		{
+			name: "columns",
+			args: []string{"-A", "-o", "pid,ppid,args"},
+			want: " PID PPID CMD\n" +
+				"   1    0 /sbin/init splash\n" +
+				"   2    0 [kthreadd]\n" +
+				"4242    1 sleep 60",
+		},
+		{
+			name: "aliases and attached argument",
+			args: []string{"-e", "-oCOMMAND,state", "-o", "pid"},
+			want: "CMD               S  PID\n" +
+				"/sbin/init splash S    1\n" +
+				"[kthreadd]        I    2\n" +
+				"sleep 60          R 4242",
+		},
+		{
+			name: "sort descending",
+			args: []string{"-e", "--sort", "-time", "-o", "comm"},
+			want: "COMMAND\nsleep\ninit\nkthreadd",
+		},
+		{
+			name: "sort by several keys",
+			args: []string{"-e", "--sort=ppid,-pid", "-o", "pid,ppid"},
+			want: " PID PPID\n   2    0\n   1    0\n4242    1",
+		},
+		{
+			name:    "unknown column",
+			args:    []string{"-e", "-o", "pid,bogus"},
+			wantErr: "ps: unknown column: bogus",
+		},
+		{
+			name:    "unknown sort key",
+			args:    []string{"-e", "--sort", "bogus"},
+			wantErr: "ps: unknown column: bogus",
+		},
+		{
+			name:    "missing column list",
+			args:    []string{"-o"},
+			wantErr: "ps: option -o requires an argument",
+		},
+		{
+			name:    "unknown option",
+			args:    []string{"-x"},
+			wantErr: "ps: unknown option: -x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newPsBuiltins(t)
+
+			out, err := b.Ps(tt.args...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestPsFull(t *testing.T) {
+	b := newPsBuiltins(t)
+
+	name := strconv.Itoa(os.Getuid())
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	kb := func(pages int) string { return strconv.Itoa(pages * os.Getpagesize() / 1024) }
+
+	out, err := b.Ps("-ef")
+	require.NoError(t, err)
+
+	var rows [][]string
+	for _, line := range strings.Split(out, "\n") {
+		rows = append(rows, strings.Fields(line))
+	}
+	assert.Equal(t, [][]string{
+		{"USER", "PID", "PPID", "S", "RSS", "TIME", "CMD"},
+		{name, "1", "0", "S", kb(10), "00:00:01", "/sbin/init", "splash"},
+		{name, "2", "0", "I", "0", "00:00:00", "[kthreadd]"},
+		{name, "4242", "1", "R", kb(20), "01:00:00", "sleep", "60"},
+	}, rows)
+}
+
+func TestPsJobs(t *testing.T) {
+	b := newPsBuiltins(t)
+	b.Jobs().Add(models.Process{PID: 4242, Job: 1, Cmd: "sleep"})
+	b.Jobs().Add(models.Process{PID: 4343, Job: 1, Cmd: "gone"})
+
+	// the shell itself is not in the fake filesystem either
+	out, err := b.Ps("-o", "comm,args", "--sort", "comm")
+	require.NoError(t, err)
+	assert.Equal(t, "COMMAND   CMD\ngone      gone\nminishell [minishell]\nsleep     sleep 60", out)
+}
@@ -0,0 +1,342 @@
+package builtins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// defaultIFS separates the fields read splits a line into when IFS is unset
+const defaultIFS = " \t\n"
+
+// errReadTimeout is returned when read -t runs out of time
+var errReadTimeout = errors.New("read: timed out")
+
+// Read reads a line from stdin and assigns its fields, split on IFS, to the
+// named variables; the last one gets the rest of the line. Without names
+// the line goes to REPLY. Options: -r keeps backslashes, -p PROMPT prints
+// a prompt on stderr, -d DELIM ends the line at DELIM instead of a newline
+// and -t SECONDS gives up after a timeout. At end of input the status is 1.
+func (b *Builtins) Read(ctx context.Context, stdio IO, args ...string) error {
+	raw := false
+	delim := byte('\n')
+	var timeout time.Duration
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+
+		for i := 1; i < len(opt); i++ {
+			if opt[i] == 'r' {
+				raw = true
+				continue
+			}
+			if strings.IndexByte("pdt", opt[i]) < 0 {
+				return &StatusError{Status: 2, Err: fmt.Errorf("read: -%c: invalid option", opt[i])}
+			}
+
+			// the value is the rest of the option or the next argument
+			value := opt[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return &StatusError{Status: 2, Err: fmt.Errorf("read: -%c: option requires an argument", opt[i])}
+				}
+				value, args = args[0], args[1:]
+			}
+
+			switch opt[i] {
+			case 'p':
+				io.WriteString(stdio.Stderr, value)
+			case 'd':
+				delim = 0
+				if value != "" {
+					delim = value[0]
+				}
+			case 't':
+				seconds, err := strconv.ParseFloat(value, 64)
+				if err != nil || seconds < 0 {
+					return &StatusError{Status: 2, Err: fmt.Errorf("read: %s: invalid timeout specification", value)}
+				}
+				timeout = time.Duration(seconds * float64(time.Second))
+			}
+			break
+		}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errReadTimeout)
+		defer cancel()
+	}
+
+	line, escaped, readErr := b.readRecord(ctx, stdio.Stdin, delim, raw)
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		if errors.Is(readErr, errReadTimeout) {
+			return &StatusError{Status: 128 + int(syscall.SIGALRM)}
+		}
+		return readErr
+	}
+
+	var values []string
+	if len(args) == 0 {
+		// REPLY gets the line without splitting
+		args = []string{"REPLY"}
+		values = []string{string(line)}
+	} else {
+		ifs, ok := b.env.Lookup("IFS")
+		if !ok {
+			ifs = defaultIFS
+		}
+		values = splitFields(line, escaped, ifs, len(args))
+	}
+
+	var errs []error
+	for i, name := range args {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		if err := b.env.Set(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("read: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if readErr != nil {
+		// end of input before the delimiter
+		return &StatusError{Status: 1}
+	}
+	return nil
+}
+
+// readRecord reads up to delim one byte at a time, so that the rest of the
+// input is left to the next command. Unless raw, a backslash quotes the
+// next byte and a backslash before the delimiter continues the line; the
+// quoted bytes are marked in escaped.
+func (b *Builtins) readRecord(ctx context.Context, r io.Reader, delim byte, raw bool) ([]byte, []bool, error) {
+	if r == nil {
+		return nil, nil, io.EOF
+	}
+
+	read := b.readByteFunc(ctx, r)
+	var line []byte
+	var escaped []bool
+	for {
+		c, err := read()
+		if err != nil {
+			return line, escaped, err
+		}
+		if c == delim {
+			return line, escaped, nil
+		}
+		if c == '\\' && !raw {
+			c, err = read()
+			if err != nil {
+				return line, escaped, err
+			}
+			if c == delim {
+				continue
+			}
+			line = append(line, c)
+			escaped = append(escaped, true)
+			continue
+		}
+		line = append(line, c)
+		escaped = append(escaped, false)
+	}
+}
+
+// readByteFunc returns a function reading single bytes from r that gives
+// up when ctx is done. Files use deadlines; other readers are read by the
+// byte pump of r.
+func (b *Builtins) readByteFunc(ctx context.Context, r io.Reader) func() (byte, error) {
+	var buf [1]byte
+
+	if f, ok := r.(*os.File); ok && f.SetReadDeadline(time.Time{}) == nil {
+		return func() (byte, error) {
+			stop := context.AfterFunc(ctx, func() { f.SetReadDeadline(time.Now()) })
+			defer stop()
+			_, err := io.ReadFull(f, buf[:])
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				f.SetReadDeadline(time.Time{})
+				return 0, context.Cause(ctx)
+			}
+			return buf[0], err
+		}
+	}
+
+	// without a way to give up there is nothing to hand over, unless an
+	// earlier read that gave up left a pump
+	p := b.pump(r, ctx.Done() != nil)
+	if p == nil {
+		return func() (byte, error) {
+			_, err := io.ReadFull(r, buf[:])
+			return buf[0], err
+		}
+	}
+	return func() (byte, error) {
+		c, err := p.read(ctx)
+		if err != nil && !errors.Is(err, context.Cause(ctx)) {
+			b.dropPump(r, p)
+		}
+		return c, err
+	}
+}
+
+// bytePump reads a reader one byte at a time in a goroutine, for reads
+// that may give up waiting. A byte that arrives after the read waiting for
+// it gave up is kept for the next one, so no input is lost between reads.
+type bytePump struct {
+	sem      chan struct{} // sem lets one read at a time wait for a byte
+	requests chan struct{}
+	results  chan byteResult
+	waiting  bool // waiting is set while a requested byte is not received
+}
+
+type byteResult struct {
+	c   byte
+	err error
+}
+
+// pump returns the pump of r, shared by all reads of r until it fails. A
+// missing pump is started if create is set, else nil is returned.
+func (b *Builtins) pump(r io.Reader, create bool) *bytePump {
+	// readers that cannot be map keys get a pump of their own
+	if !reflect.TypeOf(r).Comparable() {
+		if !create {
+			return nil
+		}
+		return startPump(r)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := b.pumps[r]
+	if p == nil && create {
+		p = startPump(r)
+		if b.pumps == nil {
+			b.pumps = map[io.Reader]*bytePump{}
+		}
+		b.pumps[r] = p
+	}
+	return p
+}
+
+// startPump starts a goroutine reading a byte of r for each request; it
+// ends at the first error
+func startPump(r io.Reader) *bytePump {
+	p := &bytePump{
+		sem:      make(chan struct{}, 1),
+		requests: make(chan struct{}, 1),
+		results:  make(chan byteResult, 1),
+	}
+	go func() {
+		var buf [1]byte
+		for range p.requests {
+			_, err := io.ReadFull(r, buf[:])
+			p.results <- byteResult{buf[0], err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// dropPump forgets the pump p of r, which stopped after an error
+func (b *Builtins) dropPump(r io.Reader, p *bytePump) {
+	if !reflect.TypeOf(r).Comparable() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pumps[r] == p {
+		delete(b.pumps, r)
+	}
+}
+
+// read returns the next byte, or the cause of ctx when it is done first.
+// Only a read that was not given a byte requests one.
+func (p *bytePump) read(ctx context.Context) (byte, error) {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return 0, context.Cause(ctx)
+	}
+	defer func() { <-p.sem }()
+
+	if !p.waiting {
+		p.requests <- struct{}{}
+		p.waiting = true
+	}
+	select {
+	case res := <-p.results:
+		p.waiting = false
+		return res.c, res.err
+	case <-ctx.Done():
+		return 0, context.Cause(ctx)
+	}
+}
+
+// splitFields splits line into at most n fields on the characters of ifs.
+// IFS whitespace around fields is dropped and runs of it count as one
+// separator; other IFS characters each end a field. Escaped bytes never
+// separate. The last field keeps the rest of the line.
+func splitFields(line []byte, escaped []bool, ifs string, n int) []string {
+	isSep := func(i int) bool {
+		return !escaped[i] && strings.IndexByte(ifs, line[i]) >= 0
+	}
+	isSpace := func(i int) bool {
+		return isSep(i) && strings.IndexByte(" \t\n", line[i]) >= 0
+	}
+	if ifs == "" {
+		return []string{string(line)}
+	}
+
+	start, end := 0, len(line)
+	for start < end && isSpace(start) {
+		start++
+	}
+	for end > start && isSpace(end-1) {
+		end--
+	}
+
+	var fields []string
+	i := start
+	for len(fields) < n-1 && i < end {
+		j := i
+		for j < end && !isSep(j) {
+			j++
+		}
+		fields = append(fields, string(line[i:j]))
+
+		// skip the separator: whitespace, at most one other IFS character,
+		// and whitespace again
+		for j < end && isSpace(j) {
+			j++
+		}
+		if j < end && isSep(j) && !isSpace(j) {
+			j++
+			for j < end && isSpace(j) {
+				j++
+			}
+		}
+		i = j
+	}
+	if i < end {
+		fields = append(fields, string(line[i:end]))
+	}
+	return fields
+}
@@ -0,0 +1,175 @@
+package builtins
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// shellOptions lists the options set can change, with their short letters;
+// options without a letter can only be set with -o
+var shellOptions = []struct {
+	letter byte
+	name   string
+}{
+	{'e', "errexit"},
+	{'u', "nounset"},
+	{0, "pipefail"},
+	{'v', "verbose"},
+	{'x', "xtrace"},
+}
+
+// shoptOptions lists the options shopt can change
+var shoptOptions = []string{"xpg_echo"}
+
+// Option reports whether the named shell option, of set or of shopt, is on
+func (b *Builtins) Option(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.options[name]
+}
+
+// setOption turns the named shell option on or off
+func (b *Builtins) setOption(name string, on bool) error {
+	for _, opt := range shellOptions {
+		if opt.name == name {
+			b.storeOption(name, on)
+			return nil
+		}
+	}
+	return fmt.Errorf("set: %s: invalid option name", name)
+}
+
+// storeOption records the state of an option
+func (b *Builtins) storeOption(name string, on bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.options == nil {
+		b.options = make(map[string]bool)
+	}
+	b.options[name] = on
+}
+
+// Set changes shell options: -e, -u, -v, -x, their + forms and -o/+o NAME
+// (e.g. pipefail). Without arguments it prints the variables, -o alone
+// prints the options.
+func (b *Builtins) Set(args ...string) (string, error) {
+	if len(args) == 0 {
+		return strings.Join(b.env.Environ(), "\n"), nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			i++
+			if i < len(args) {
+				return "", fmt.Errorf("set: positional parameters are not supported")
+			}
+			break
+		}
+		if len(arg) < 2 || (arg[0] != '-' && arg[0] != '+') {
+			return "", fmt.Errorf("set: %s: invalid argument", arg)
+		}
+
+		on := arg[0] == '-'
+		for _, letter := range []byte(arg[1:]) {
+			if letter == 'o' {
+				i++
+				if i >= len(args) {
+					return b.listOptions(), nil
+				}
+				if err := b.setOption(args[i], on); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if err := b.setOption(optionName(letter), on); err != nil {
+				return "", fmt.Errorf("set: %c%c: invalid option", arg[0], letter)
+			}
+		}
+	}
+	return "", nil
+}
+
+// optionName returns the long name of a short option, or "" if unknown
+func optionName(letter byte) string {
+	for _, opt := range shellOptions {
+		if opt.letter != 0 && opt.letter == letter {
+			return opt.name
+		}
+	}
+	return ""
+}
+
+// listOptions formats the state of every option like set -o in bash
+func (b *Builtins) listOptions() string {
+	var sb strings.Builder
+	for _, opt := range shellOptions {
+		state := "off"
+		if b.Option(opt.name) {
+			state = "on"
+		}
+		fmt.Fprintf(&sb, "%-15s\t%s\n", opt.name, state)
+	}
+	return sb.String()
+}
+
+// Shopt sets (-s) or unsets (-u) shell options such as xpg_echo. Without
+// -s or -u it prints the state of the named options, or of all of them;
+// -q prints nothing and only sets the status.
+func (b *Builtins) Shopt(args ...string) (string, error) {
+	set, unset, quiet := false, false, false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 's':
+				set = true
+			case 'u':
+				unset = true
+			case 'q':
+				quiet = true
+			default:
+				return "", &StatusError{Status: 2, Err: fmt.Errorf("shopt: -%c: invalid option", flag)}
+			}
+		}
+		args = args[1:]
+	}
+	if set && unset {
+		return "", &StatusError{Status: 2, Err: fmt.Errorf("shopt: cannot set and unset shell options simultaneously")}
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = shoptOptions
+	}
+	for _, name := range names {
+		if !slices.Contains(shoptOptions, name) {
+			return "", fmt.Errorf("shopt: %s: invalid shell option name", name)
+		}
+	}
+
+	if set || unset {
+		for _, name := range names {
+			b.storeOption(name, set)
+		}
+		return "", nil
+	}
+
+	var sb strings.Builder
+	allOn := true
+	for _, name := range names {
+		state := "off"
+		if b.Option(name) {
+			state = "on"
+		} else {
+			allOn = false
+		}
+		if !quiet {
+			fmt.Fprintf(&sb, "%-15s\t%s\n", name, state)
+		}
+	}
+	if !allOn {
+		return sb.String(), &StatusError{Status: 1}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,51 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// StatusError is returned by commands that fail with a specific exit status
+type StatusError struct {
+	Status int   // Status is the exit status of the command.
+	Err    error // Err describes the failure; nil when the status says it all.
+}
+
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Status)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// ExitStatus converts the error of a command into its exit status, the way
+// a shell reports it in $?
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+
+	if errors.Is(err, ErrCmdNotFound) {
+		return 127
+	}
+	return 1
+}
@@ -0,0 +1,140 @@
+package builtins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinName is how headers and messages call the standard input
+const stdinName = "standard input"
+
+// eachInput calls fn with every named input in turn, opened relative to the
+// shell's directory; no names or "-" stand for stdin. Inputs that cannot be
+// opened are skipped and reported in the result with cmd as prefix, so
+// that the others are still processed. An error from fn stops at once.
+func (b *Builtins) eachInput(ctx context.Context, stdin io.Reader, cmd string, names []string, fn func(name string, r io.Reader) error) error {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var errs []error
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r, closeInput, err := b.openInput(stdin, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmd, err))
+			continue
+		}
+		err = fn(name, contextReader{ctx, r})
+		closeInput()
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// openInput opens a named input; "-" is stdin, which is left open
+func (b *Builtins) openInput(stdin io.Reader, name string) (io.Reader, func(), error) {
+	if name == "-" {
+		if stdin == nil {
+			stdin = strings.NewReader("")
+		}
+		return stdin, func() {}, nil
+	}
+
+	f, err := os.Open(b.Abs(name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, errors.Unwrap(err))
+	}
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: is a directory", name)
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// createOutput opens a file named by a builtin for writing, relative to
+// the shell's directory; every builtin creates its files through it
+func (b *Builtins) createOutput(name string, appending bool) (*os.File, error) {
+	if err := b.CheckWrite(name); err != nil {
+		return nil, err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appending {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(b.Abs(name), flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, errors.Unwrap(err))
+	}
+	return f, nil
+}
+
+// displayName is the name of an input in headers and messages
+func displayName(name string) string {
+	if name == "-" {
+		return stdinName
+	}
+	return name
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// eachLine calls fn with every line of r, including its newline; the last
+// line may lack one
+func eachLine(r io.Reader, fn func(line []byte) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// prefixEach prefixes with cmd every error that err joins, as the message
+// of each input that failed
+func prefixEach(cmd string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, fmt.Errorf("%s: %w", cmd, err))
+	}
+	return errors.Join(errs...)
+}
+
+// usageError reports a wrong invocation of a builtin with status 2
+func usageError(cmd, format string, args ...any) error {
+	return &StatusError{Status: 2, Err: fmt.Errorf(cmd+": "+format, args...)}
+}
@@ -0,0 +1,186 @@
+package builtins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// followInterval is how often tail -f looks for new data
+const followInterval = 100 * time.Millisecond
+
+// followedFile is a file tail -f watches, with the size already printed
+type followedFile struct {
+	name   string
+	offset int64
+}
+
+// Tail prints the last 10 lines of the files, or of stdin. -n N and -c N
+// print the last N lines or bytes instead, and -n +N starts at line N.
+// With -f tail then waits for the files to grow and prints what is
+// appended until it is interrupted. Headers are printed as by head.
+func (b *Builtins) Tail(ctx context.Context, stdio IO, args ...string) error {
+	opts, names, err := parseCountOptions("tail", args, true)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(stdio.Stdout)
+	headers := headerWriter{out: out, enabled: opts.verbose || (len(names) > 1 && !opts.quiet)}
+	var followed []followedFile
+	last := ""
+	err = b.eachInput(ctx, stdio.Stdin, "tail", names, func(name string, r io.Reader) error {
+		headers.header(name)
+		last = name
+		counter := &countingReader{r: r}
+		if err := tailInput(out, counter, opts); err != nil {
+			return err
+		}
+		if name != "-" {
+			followed = append(followed, followedFile{name: name, offset: counter.n})
+		}
+		return nil
+	})
+	if flushErr := out.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil || !opts.follow || len(followed) == 0 {
+		return err
+	}
+	return b.follow(ctx, stdio, out, followed, headers, last)
+}
+
+// tailInput prints the requested end of one input
+func tailInput(w io.Writer, r io.Reader, opts countOptions) error {
+	switch {
+	case opts.sign == '+' && opts.bytes:
+		if opts.count > 1 {
+			if _, err := io.CopyN(io.Discard, r, opts.count-1); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+		}
+		_, err := io.Copy(w, r)
+		return err
+	case opts.sign == '+':
+		skip := opts.count - 1
+		return eachLine(r, func(line []byte) error {
+			if skip > 0 {
+				skip--
+				return nil
+			}
+			_, err := w.Write(line)
+			return err
+		})
+	case opts.bytes:
+		var held []byte
+		buf := make([]byte, 32*1024)
+		for {
+			k, err := r.Read(buf)
+			held = append(held, buf[:k]...)
+			if extra := int64(len(held)) - opts.count; extra > 0 {
+				held = append(held[:0], held[extra:]...)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		_, err := w.Write(held)
+		return err
+	}
+
+	var held [][]byte
+	err := eachLine(r, func(line []byte) error {
+		held = append(held, line)
+		if int64(len(held)) > opts.count {
+			held = held[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range held {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow prints what is appended to the files until ctx is done; a file
+// that shrinks is read again from the start
+func (b *Builtins) follow(ctx context.Context, stdio IO, out *bufio.Writer, files []followedFile, headers headerWriter, last string) error {
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		for i := range files {
+			f := &files[i]
+			info, err := os.Stat(b.Abs(f.name))
+			if err != nil {
+				continue
+			}
+			if info.Size() < f.offset {
+				fmt.Fprintf(stdio.Stderr, "tail: %s: file truncated\n", f.name)
+				f.offset = 0
+			}
+			if info.Size() == f.offset {
+				continue
+			}
+
+			if last != f.name {
+				headers.header(f.name)
+				last = f.name
+			}
+			n, err := copyFrom(out, b.Abs(f.name), f.offset)
+			f.offset += n
+			if err != nil {
+				return err
+			}
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// copyFrom copies a file from the given offset on
+func copyFrom(w io.Writer, path string, offset int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(w, f)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,61 @@
+package builtins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Tee copies stdin to stdout and to the files, which are truncated unless
+// -a appends to them. Files that cannot be opened are reported and the
+// copy goes on to the others.
+func (b *Builtins) Tee(ctx context.Context, stdio IO, args ...string) error {
+	appending := false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		for _, flag := range opt[1:] {
+			switch flag {
+			case 'a':
+				appending = true
+			case 'i':
+				// interrupts stop the shell's pipeline, not just tee
+			default:
+				return usageError("tee", "-%c: invalid option", flag)
+			}
+		}
+	}
+
+	writers := []io.Writer{stdio.Stdout}
+	var files []*os.File
+	var errs []error
+	for _, name := range args {
+		f, err := b.createOutput(name, appending)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tee: %w", err))
+			continue
+		}
+		files = append(files, f)
+		writers = append(writers, f)
+	}
+
+	stdin := stdio.Stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), contextReader{ctx, stdin}); err != nil {
+		errs = append(errs, err)
+	}
+	for _, f := range files {
+		if err := f.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("tee: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
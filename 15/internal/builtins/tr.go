@@ -0,0 +1,283 @@
+package builtins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// trClasses are the character classes tr sets may name as [:name:]
+var trClasses = map[string]func(c byte) bool{
+	"alnum":  func(c byte) bool { return isAlpha(c) || isDigit(c) },
+	"alpha":  isAlpha,
+	"blank":  func(c byte) bool { return c == ' ' || c == '\t' },
+	"cntrl":  func(c byte) bool { return c < ' ' || c == 0x7f },
+	"digit":  isDigit,
+	"graph":  func(c byte) bool { return c > ' ' && c < 0x7f },
+	"lower":  func(c byte) bool { return c >= 'a' && c <= 'z' },
+	"print":  func(c byte) bool { return c >= ' ' && c < 0x7f },
+	"punct":  func(c byte) bool { return c > ' ' && c < 0x7f && !isAlpha(c) && !isDigit(c) },
+	"space":  func(c byte) bool { return c == ' ' || (c >= '\t' && c <= '\r') },
+	"upper":  func(c byte) bool { return c >= 'A' && c <= 'Z' },
+	"xdigit": func(c byte) bool { return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') },
+}
+
+func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// Tr copies stdin to stdout translating the bytes of SET1 into those of
+// SET2 at the same position; a short SET2 is padded with its last byte.
+// -d deletes the bytes of SET1 instead, -s squeezes runs of a byte of the
+// last set given into one, and -c takes the complement of SET1. Sets are
+// made of bytes, escapes such as \n or \012, ranges like a-z, classes
+// like [:upper:] and equivalence classes like [=a=], which in the C locale
+// are the byte itself. [c*n] repeats c n times, and [c*] in SET2 until it
+// is as long as SET1.
+func (b *Builtins) Tr(ctx context.Context, stdio IO, args ...string) error {
+	var complement, deleting, squeezing bool
+	for len(args) > 0 && len(args[0]) > 1 && args[0][0] == '-' {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		for _, flag := range opt[1:] {
+			switch flag {
+			case 'c', 'C':
+				complement = true
+			case 'd':
+				deleting = true
+			case 's':
+				squeezing = true
+			default:
+				return usageError("tr", "-%c: invalid option", flag)
+			}
+		}
+	}
+
+	minSets, maxSets := 2, 2
+	switch {
+	case deleting && !squeezing:
+		minSets, maxSets = 1, 1
+	case squeezing && !deleting:
+		minSets = 1
+	}
+	switch {
+	case len(args) == 0:
+		return usageError("tr", "missing operand")
+	case len(args) < minSets:
+		return usageError("tr", "missing operand after '%s'", args[len(args)-1])
+	case len(args) > maxSets:
+		return usageError("tr", "extra operand '%s'", args[maxSets])
+	}
+
+	sets := make([][]byte, len(args))
+	fill := trFill{at: -1}
+	for i, arg := range args {
+		set, f, err := parseTrSet(arg, i == 1)
+		if err != nil {
+			return &StatusError{Status: 1, Err: fmt.Errorf("tr: %w", err)}
+		}
+		sets[i] = set
+		if i == 1 {
+			fill = f
+		}
+	}
+	if complement {
+		sets[0] = complementSet(sets[0])
+	}
+	if fill.at >= 0 {
+		sets[1] = fill.expand(sets[1], len(sets[0]))
+	}
+
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i)
+	}
+	var deleted, squeezed [256]bool
+	switch {
+	case deleting:
+		for _, c := range sets[0] {
+			deleted[c] = true
+		}
+	case len(sets) == 2:
+		if len(sets[1]) == 0 {
+			return &StatusError{Status: 1, Err: errors.New("tr: when not truncating set1, string2 must be non-empty")}
+		}
+		for i, c := range sets[0] {
+			table[c] = sets[1][min(i, len(sets[1])-1)]
+		}
+	}
+	if squeezing {
+		for _, c := range sets[len(sets)-1] {
+			squeezed[c] = true
+		}
+	}
+
+	stdin := stdio.Stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	r := bufio.NewReader(contextReader{ctx, stdin})
+	out := bufio.NewWriter(stdio.Stdout)
+	last := -1
+	for {
+		c, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if deleted[c] {
+			continue
+		}
+		c = table[c]
+		if squeezed[c] && int(c) == last {
+			continue
+		}
+		last = int(c)
+		if err := out.WriteByte(c); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// trFill is the position of a [c*] repeat in a set, -1 when there is none
+type trFill struct {
+	at int
+	c  byte
+}
+
+// expand inserts as many copies of the byte as make set n bytes long
+func (f trFill) expand(set []byte, n int) []byte {
+	count := max(n-len(set), 0)
+	return slices.Concat(set[:f.at], bytes.Repeat([]byte{f.c}, count), set[f.at:])
+}
+
+// parseTrSet expands the escapes, ranges, classes and repeats of a tr set.
+// [c*] is only allowed in the second set; its position is returned, to be
+// filled once the length of the first set is known.
+func parseTrSet(s string, second bool) ([]byte, trFill, error) {
+	var set []byte
+	fill := trFill{at: -1}
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "[:") {
+			if end := strings.Index(s[i+2:], ":]"); end >= 0 {
+				name := s[i+2 : i+2+end]
+				class, ok := trClasses[name]
+				if !ok {
+					return nil, fill, fmt.Errorf("invalid character class '%s'", name)
+				}
+				for c := 0; c < 256; c++ {
+					if class(byte(c)) {
+						set = append(set, byte(c))
+					}
+				}
+				i += end + 4
+				continue
+			}
+		}
+		if strings.HasPrefix(s[i:], "[=") && i+2 < len(s) {
+			c, n := trChar(s[i+2:])
+			if strings.HasPrefix(s[i+2+n:], "=]") {
+				set = append(set, c)
+				i += n + 4
+				continue
+			}
+		}
+		if s[i] == '[' && i+1 < len(s) {
+			c, n := trChar(s[i+1:])
+			j := i + 1 + n
+			if end := strings.IndexByte(s[j:], ']'); strings.HasPrefix(s[j:], "*") && end >= 0 {
+				count, err := parseTrRepeat(s[j+1 : j+end])
+				if err != nil {
+					return nil, fill, err
+				}
+				if count == 0 {
+					if !second {
+						return nil, fill, errors.New("the [c*] repeat construct may not appear in string1")
+					}
+					if fill.at >= 0 {
+						return nil, fill, errors.New("only one [c*] repeat construct may appear in string2")
+					}
+					fill = trFill{at: len(set), c: c}
+				}
+				set = append(set, bytes.Repeat([]byte{c}, count)...)
+				i = j + end + 1
+				continue
+			}
+		}
+
+		c, n := trChar(s[i:])
+		i += n
+		if i+1 < len(s) && s[i] == '-' {
+			hi, m := trChar(s[i+1:])
+			if hi < c {
+				return nil, fill, fmt.Errorf("range-endpoints of '%s' are in reverse collating sequence order", s[i-n:i+1+m])
+			}
+			for x := int(c); x <= int(hi); x++ {
+				set = append(set, byte(x))
+			}
+			i += 1 + m
+			continue
+		}
+		set = append(set, c)
+	}
+	return set, fill, nil
+}
+
+// parseTrRepeat parses the n of [c*n], octal with a leading 0; empty is 0
+func parseTrRepeat(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	base := 10
+	if s[0] == '0' {
+		base = 8
+	}
+	n, err := strconv.ParseUint(s, base, 31)
+	if err != nil {
+		return 0, fmt.Errorf("invalid repeat count '%s' in [c*n] construct", s)
+	}
+	return int(n), nil
+}
+
+// trChar reads one possibly escaped byte of a set and returns how many
+// bytes of s it used
+func trChar(s string) (byte, int) {
+	if s[0] != '\\' || len(s) == 1 {
+		return s[0], 1
+	}
+	if n, value := parseDigits(s[1:], 8, 3); n > 0 && value < 256 {
+		return byte(value), 1 + n
+	}
+	text, _ := unescape(s[:2], printfEscapes)
+	if len(text) == 1 {
+		return text[0], 2
+	}
+	// an unknown escape stands for the character itself
+	return s[1], 2
+}
+
+// complementSet returns the bytes not in set, in ascending order
+func complementSet(set []byte) []byte {
+	var in [256]bool
+	for _, c := range set {
+		in[c] = true
+	}
+	var out []byte
+	for c := 0; c < 256; c++ {
+		if !in[c] {
+			out = append(out, byte(c))
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package builtins
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"syscall"
+
+	"minishell/internal/signals"
+)
+
+// Trap sets the commands run on EXIT, on ERR or when a signal arrives:
+// "trap 'cmd' EXIT INT". An action of "-" resets the condition, an empty
+// one ignores it. Without arguments, or with -p, the traps are printed.
+func (b *Builtins) Trap(args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "-p" {
+		return b.listTraps(), nil
+	}
+	if len(args) == 1 {
+		return "", fmt.Errorf("trap: usage: trap [action condition ...]")
+	}
+
+	action := args[0]
+	for _, cond := range args[1:] {
+		name, err := trapCondition(cond)
+		if err != nil {
+			return "", fmt.Errorf("trap: %w", err)
+		}
+
+		b.mu.Lock()
+		if action == "-" {
+			delete(b.traps, name)
+		} else {
+			if b.traps == nil {
+				b.traps = make(map[string]string)
+			}
+			b.traps[name] = action
+		}
+		b.mu.Unlock()
+	}
+	return "", nil
+}
+
+// TrapAction returns the command trapped for a condition such as "EXIT",
+// "ERR" or "INT"; it is empty if the condition is ignored
+func (b *Builtins) TrapAction(name string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	action, ok := b.traps[name]
+	return action, ok
+}
+
+// ResetTrap removes the trap of a condition
+func (b *Builtins) ResetTrap(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.traps, name)
+}
+
+// TrappedSignals returns the signals that have a trap
+func (b *Builtins) TrappedSignals() []syscall.Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var sigs []syscall.Signal
+	for name := range b.traps {
+		if sig, err := signals.Parse(name); err == nil {
+			sigs = append(sigs, sig)
+		}
+	}
+	slices.Sort(sigs)
+	return sigs
+}
+
+// trapCondition returns the canonical name of a trap condition
+func trapCondition(cond string) (string, error) {
+	switch strings.ToUpper(cond) {
+	case "EXIT", "0":
+		return "EXIT", nil
+	case "ERR":
+		return "ERR", nil
+	}
+
+	sig, err := signals.Parse(cond)
+	if err != nil {
+		return "", err
+	}
+	if sig == syscall.SIGKILL || sig == syscall.SIGSTOP {
+		return "", fmt.Errorf("%s: cannot be trapped", cond)
+	}
+	return signals.Name(sig), nil
+}
+
+// listTraps formats the traps so that they can be read back
+func (b *Builtins) listTraps() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(b.traps)) {
+		action := strings.ReplaceAll(b.traps[name], "'", `'\''`)
+		fmt.Fprintf(&sb, "trap -- '%s' %s\n", action, name)
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package builtins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Uniq prints the lines of INPUT, or of stdin, to OUTPUT, or to stdout,
+// dropping lines equal to the one before. -c prefixes each line with the
+// number of times it occurred, -d prints only repeated lines, -u only
+// those that are not repeated, and -i ignores case.
+func (b *Builtins) Uniq(ctx context.Context, stdio IO, args ...string) error {
+	var countLines, repeated, unique, ignoreCase bool
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		for _, flag := range opt[1:] {
+			switch flag {
+			case 'c':
+				countLines = true
+			case 'd':
+				repeated = true
+			case 'u':
+				unique = true
+			case 'i':
+				ignoreCase = true
+			default:
+				return usageError("uniq", "-%c: invalid option", flag)
+			}
+		}
+	}
+	if len(args) > 2 {
+		return usageError("uniq", "extra operand '%s'", args[2])
+	}
+
+	stdout := stdio.Stdout
+	if len(args) == 2 && args[1] != "-" {
+		f, err := b.createOutput(args[1], false)
+		if err != nil {
+			return fmt.Errorf("uniq: %w", err)
+		}
+		defer f.Close()
+		stdout = f
+	}
+	out := bufio.NewWriter(stdout)
+
+	var prev []byte
+	n := 0
+	flush := func() error {
+		if n == 0 || (repeated && n == 1) || (unique && n > 1) {
+			return nil
+		}
+		if countLines {
+			fmt.Fprintf(out, "%7d ", n)
+		}
+		out.Write(prev)
+		return out.WriteByte('\n')
+	}
+	equal := bytes.Equal
+	if ignoreCase {
+		equal = bytes.EqualFold
+	}
+
+	err := b.eachInput(ctx, stdio.Stdin, "uniq", args[:min(len(args), 1)], func(name string, r io.Reader) error {
+		return eachLine(r, func(line []byte) error {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			if n > 0 && equal(line, prev) {
+				n++
+				return nil
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			prev, n = line, 1
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
@@ -0,0 +1,171 @@
+package builtins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// counts are what wc counts in an input
+type counts struct {
+	lines, words, chars, bytes int64
+}
+
+func (c *counts) add(o counts) {
+	c.lines += o.lines
+	c.words += o.words
+	c.chars += o.chars
+	c.bytes += o.bytes
+}
+
+// Wc prints the newline, word and byte counts of the files, or of stdin,
+// and their total when there are several. -l, -w, -m (characters) and -c
+// select the counts, which are always printed in that order.
+func (b *Builtins) Wc(ctx context.Context, stdio IO, args ...string) error {
+	var lines, words, chars, bytes bool
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		for _, flag := range opt[1:] {
+			switch flag {
+			case 'l':
+				lines = true
+			case 'w':
+				words = true
+			case 'm':
+				chars = true
+			case 'c':
+				bytes = true
+			default:
+				return usageError("wc", "-%c: invalid option", flag)
+			}
+		}
+	}
+	if !lines && !words && !chars && !bytes {
+		lines, words, bytes = true, true, true
+	}
+
+	selected := 0
+	for _, on := range []bool{lines, words, chars, bytes} {
+		if on {
+			selected++
+		}
+	}
+	width := 1
+	if len(args) > 1 || selected > 1 {
+		width = b.countWidth(stdio.Stdin, args)
+	}
+
+	print := func(c counts, name string) error {
+		var fields []string
+		for _, f := range []struct {
+			on    bool
+			value int64
+		}{{lines, c.lines}, {words, c.words}, {chars, c.chars}, {bytes, c.bytes}} {
+			if f.on {
+				fields = append(fields, fmt.Sprintf("%*d", width, f.value))
+			}
+		}
+		if name != "" {
+			fields = append(fields, name)
+		}
+		_, err := fmt.Fprintln(stdio.Stdout, strings.Join(fields, " "))
+		return err
+	}
+
+	var total counts
+	err := b.eachInput(ctx, stdio.Stdin, "wc", args, func(name string, r io.Reader) error {
+		c, err := count(r)
+		if err != nil {
+			return fmt.Errorf("wc: %s: %w", displayName(name), err)
+		}
+		total.add(c)
+		if len(args) == 0 {
+			name = ""
+		}
+		return print(c, name)
+	})
+	if len(args) > 1 {
+		if printErr := print(total, "total"); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	return err
+}
+
+// countWidth is the width of the counts, which like GNU wc is the number
+// of digits of the total size of the regular files, and at least 7 when
+// some input has no known size
+func (b *Builtins) countWidth(stdin io.Reader, names []string) int {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	minimum := 1
+	var size int64
+	for i, name := range names {
+		var info os.FileInfo
+		var err error
+		if name == "-" {
+			f, ok := stdin.(*os.File)
+			if !ok {
+				minimum = 7
+				continue
+			}
+			info, err = f.Stat()
+		} else {
+			info, err = os.Stat(b.Abs(name))
+		}
+		if err != nil {
+			if i == 0 {
+				return 1
+			}
+			continue
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		} else {
+			minimum = 7
+		}
+	}
+	return max(len(strconv.FormatInt(size, 10)), minimum)
+}
+
+// count counts the lines, words, UTF-8 characters and bytes of r
+func count(r io.Reader) (counts, error) {
+	var c counts
+	inWord := false
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, ch := range buf[:n] {
+			if ch == '\n' {
+				c.lines++
+			}
+			// continuation bytes are part of the previous character
+			if ch&0xc0 != 0x80 {
+				c.chars++
+			}
+			if ch == ' ' || (ch >= '\t' && ch <= '\r') {
+				inWord = false
+			} else if !inWord {
+				inWord = true
+				c.words++
+			}
+		}
+		c.bytes += int64(n)
+		if errors.Is(err, io.EOF) {
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+}
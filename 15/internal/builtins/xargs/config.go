@@ -0,0 +1,78 @@
+package xargs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultCommand is run when xargs is given no command
+const defaultCommand = "echo"
+
+// Config for xargs
+type Config struct {
+	MaxArgs      int    // MaxArgs is the most items per command, 0 for no limit
+	Null         bool   // Null separates the items with NUL bytes
+	Replace      string // Replace is the string -I replaces with each line
+	Parallel     int    // Parallel is how many commands may run at once, 0 for no limit
+	NoRunIfEmpty bool
+	Command      []string
+}
+
+// ParseConfig parses command line arguments into Config. The options are
+// -n N, -0, -I R, -P N and -r; the rest is the command.
+func ParseConfig(args ...string) (Config, error) {
+	cfg := Config{Parallel: 1}
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		opt := args[0]
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+
+		for i := 1; i < len(opt); i++ {
+			switch opt[i] {
+			case '0':
+				cfg.Null = true
+				continue
+			case 'r':
+				cfg.NoRunIfEmpty = true
+				continue
+			case 'n', 'I', 'P':
+			default:
+				return cfg, fmt.Errorf("-%c: invalid option", opt[i])
+			}
+
+			// the value is the rest of the option or the next argument
+			value := opt[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return cfg, fmt.Errorf("-%c: option requires an argument", opt[i])
+				}
+				value, args = args[0], args[1:]
+			}
+
+			if opt[i] == 'I' {
+				cfg.Replace = value
+				break
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 || (opt[i] == 'n' && n == 0) {
+				return cfg, fmt.Errorf("-%c: invalid number '%s'", opt[i], value)
+			}
+			if opt[i] == 'n' {
+				cfg.MaxArgs = n
+			} else {
+				cfg.Parallel = n
+			}
+			break
+		}
+	}
+
+	cfg.Command = args
+	if len(cfg.Command) == 0 {
+		cfg.Command = []string{defaultCommand}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,209 @@
+package xargs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxCommandSize bounds the bytes of arguments given to one command when
+// -n does not limit them, like the default of GNU xargs
+const maxCommandSize = 128 * 1024
+
+// Exit statuses of xargs, as in GNU findutils
+const (
+	StatusFailed   = 123 // a command exited with a status from 1 to 125
+	StatusStopped  = 124 // a command exited with 255
+	StatusSignaled = 125 // a command was killed by a signal
+	StatusNoExec   = 126 // a command could not be run
+	StatusNotFound = 127 // a command was not found
+)
+
+// stopAllStatus is the exit status by which a command stops xargs
+const stopAllStatus = 255
+
+// signaledMinimum is the lowest status of a command killed by a signal,
+// which the shell reports as 128 plus the signal number
+const signaledMinimum = 129
+
+// ErrUnmatchedQuote is returned for input with an unterminated quote
+var ErrUnmatchedQuote = errors.New("unmatched quote")
+
+// Runner runs a command and returns its exit status
+type Runner func(ctx context.Context, args []string) int
+
+// Run reads items from r and runs the command of cfg with them, in batches
+// of at most MaxArgs items or, with Replace, once per input line with the
+// line in place of Replace. Up to Parallel commands run at once. It
+// returns the exit status of xargs.
+func Run(ctx context.Context, r io.Reader, cfg Config, run Runner) (int, error) {
+	items, err := readItems(r, cfg)
+	if err != nil {
+		return 1, err
+	}
+
+	commands := buildCommands(items, cfg)
+	if len(commands) == 0 && !cfg.NoRunIfEmpty && cfg.Replace == "" {
+		commands = [][]string{cfg.Command}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		failed  bool
+		stopped int
+	)
+	limit := cfg.Parallel
+	if limit == 0 {
+		limit = len(commands)
+	}
+	slots := make(chan struct{}, max(limit, 1))
+
+	for _, args := range commands {
+		slots <- struct{}{}
+		mu.Lock()
+		stop := stopped != 0
+		mu.Unlock()
+		if stop || ctx.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func(args []string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			status := run(ctx, args)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case status == 0:
+			case status == stopAllStatus:
+				stopped = StatusStopped
+			case status == StatusNotFound || status == StatusNoExec:
+				stopped = status
+			case status >= signaledMinimum:
+				stopped = StatusSignaled
+			default:
+				failed = true
+			}
+		}(args)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return 1, err
+	}
+	switch {
+	case stopped != 0:
+		return stopped, nil
+	case failed:
+		return StatusFailed, nil
+	}
+	return 0, nil
+}
+
+// buildCommands groups the items into command lines
+func buildCommands(items []string, cfg Config) [][]string {
+	var commands [][]string
+	if cfg.Replace != "" {
+		for _, item := range items {
+			args := make([]string, len(cfg.Command))
+			for i, arg := range cfg.Command {
+				args[i] = strings.ReplaceAll(arg, cfg.Replace, item)
+			}
+			commands = append(commands, args)
+		}
+		return commands
+	}
+
+	var batch []string
+	size := 0
+	for _, item := range items {
+		full := (cfg.MaxArgs > 0 && len(batch) == cfg.MaxArgs) ||
+			(cfg.MaxArgs == 0 && len(batch) > 0 && size+len(item)+1 > maxCommandSize)
+		if full {
+			commands = append(commands, append(append([]string{}, cfg.Command...), batch...))
+			batch, size = nil, 0
+		}
+		batch = append(batch, item)
+		size += len(item) + 1
+	}
+	if len(batch) > 0 {
+		commands = append(commands, append(append([]string{}, cfg.Command...), batch...))
+	}
+	return commands
+}
+
+// readItems splits the input into items: on NUL bytes with Null, into
+// lines with Replace, and otherwise on blanks and newlines, where single
+// and double quotes and backslashes protect them
+func readItems(r io.Reader, cfg Config) ([]string, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	input := string(data)
+
+	if cfg.Null {
+		items := strings.Split(input, "\x00")
+		if items[len(items)-1] == "" {
+			items = items[:len(items)-1]
+		}
+		return items, nil
+	}
+
+	if cfg.Replace != "" {
+		var items []string
+		scanner := bufio.NewScanner(strings.NewReader(input))
+		scanner.Buffer(nil, maxCommandSize)
+		for scanner.Scan() {
+			if line := strings.TrimLeft(scanner.Text(), " \t"); line != "" {
+				items = append(items, line)
+			}
+		}
+		return items, scanner.Err()
+	}
+
+	var items []string
+	var cur strings.Builder
+	inItem := false
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; c {
+		case ' ', '\t', '\n':
+			if inItem {
+				items = append(items, cur.String())
+				cur.Reset()
+				inItem = false
+			}
+		case '\'', '"':
+			end := strings.IndexByte(input[i+1:], c)
+			if end < 0 || strings.Contains(input[i+1:i+1+end], "\n") {
+				return nil, ErrUnmatchedQuote
+			}
+			cur.WriteString(input[i+1 : i+1+end])
+			inItem = true
+			i += end + 1
+		case '\\':
+			if i+1 < len(input) {
+				i++
+			}
+			cur.WriteByte(input[i])
+			inItem = true
+		default:
+			cur.WriteByte(c)
+			inItem = true
+		}
+	}
+	if inItem {
+		items = append(items, cur.String())
+	}
+	return items, nil
+}
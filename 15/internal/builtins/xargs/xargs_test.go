@@ -0,0 +1,154 @@
+package xargs_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/builtins/xargs"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		input      string
+		wantCalls  [][]string
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:      "default command",
+			input:     "a b\nc\n",
+			wantCalls: [][]string{{"echo", "a", "b", "c"}},
+		},
+		{
+			name:      "max args",
+			args:      []string{"-n", "2", "rm"},
+			input:     "a b c",
+			wantCalls: [][]string{{"rm", "a", "b"}, {"rm", "c"}},
+		},
+		{
+			name:      "quotes and backslashes",
+			args:      []string{"-n1", "cat"},
+			input:     `'a b' "c d" e\ f`,
+			wantCalls: [][]string{{"cat", "a b"}, {"cat", "c d"}, {"cat", "e f"}},
+		},
+		{
+			name:      "null separated",
+			args:      []string{"-0", "ls"},
+			input:     "a b\x00c\nd\x00",
+			wantCalls: [][]string{{"ls", "a b", "c\nd"}},
+		},
+		{
+			name:      "replace",
+			args:      []string{"-I", "{}", "mv", "{}", "{}.bak"},
+			input:     "one\n  two words\n\n",
+			wantCalls: [][]string{{"mv", "one", "one.bak"}, {"mv", "two words", "two words.bak"}},
+		},
+		{
+			name:      "empty input",
+			args:      []string{"ls"},
+			wantCalls: [][]string{{"ls"}},
+		},
+		{
+			name:  "no run if empty",
+			args:  []string{"-r", "ls"},
+			input: " \n",
+		},
+		{
+			name:       "failed command",
+			args:       []string{"-n", "1", "fail"},
+			input:      "a b",
+			wantCalls:  [][]string{{"fail", "a"}, {"fail", "b"}},
+			wantStatus: xargs.StatusFailed,
+		},
+		{
+			name:       "command stops xargs",
+			args:       []string{"-n", "1", "stop"},
+			input:      "a b",
+			wantCalls:  [][]string{{"stop", "a"}},
+			wantStatus: xargs.StatusStopped,
+		},
+		{
+			name:       "unmatched quote",
+			input:      "'a",
+			wantStatus: 1,
+			wantErr:    xargs.ErrUnmatchedQuote,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := xargs.ParseConfig(tt.args...)
+			require.NoError(t, err)
+
+			var calls [][]string
+			run := func(ctx context.Context, args []string) int {
+				calls = append(calls, args)
+				switch args[0] {
+				case "fail":
+					return 1
+				case "stop":
+					return 255
+				}
+				return 0
+			}
+
+			status, err := xargs.Run(context.Background(), strings.NewReader(tt.input), cfg, run)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	cfg, err := xargs.ParseConfig("-P", "3", "-n", "1", "work")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	running, most := 0, 0
+	release := make(chan struct{})
+	var once sync.Once
+	run := func(ctx context.Context, args []string) int {
+		mu.Lock()
+		running++
+		most = max(most, running)
+		if running == 3 {
+			once.Do(func() { close(release) })
+		}
+		mu.Unlock()
+
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return 0
+	}
+
+	status, err := xargs.Run(context.Background(), strings.NewReader("1 2 3 4 5 6"), cfg, run)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, 3, most)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := xargs.ParseConfig("-0r", "-n3", "-P", "0", "grep", "-n", "x")
+	require.NoError(t, err)
+	assert.Equal(t, xargs.Config{
+		MaxArgs:      3,
+		Null:         true,
+		NoRunIfEmpty: true,
+		Command:      []string{"grep", "-n", "x"},
+	}, cfg)
+
+	for _, args := range [][]string{{"-n"}, {"-n", "0"}, {"-P", "x"}, {"-z"}} {
+		_, err := xargs.ParseConfig(args...)
+		assert.Error(t, err, args)
+	}
+}
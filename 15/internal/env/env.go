@@ -0,0 +1,94 @@
+// Package env keeps the variables of a shell separately from the
+// environment of the Go process
+package env
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrReadonly is returned when a read-only variable is changed
+var ErrReadonly = errors.New("readonly variable")
+
+// Env is a goroutine-safe set of shell variables.
+type Env struct {
+	mu       sync.RWMutex
+	vars     map[string]string
+	readonly map[string]bool
+}
+
+// New returns variables initialized from KEY=VALUE pairs, as returned by os.Environ
+func New(environ []string) *Env {
+	e := &Env{vars: make(map[string]string, len(environ))}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && name != "" {
+			e.vars[name] = value
+		}
+	}
+	return e
+}
+
+// Get returns the value of a variable, or an empty string if it is unset
+func (e *Env) Get(name string) string {
+	value, _ := e.Lookup(name)
+	return value
+}
+
+// Lookup returns the value of a variable and whether it is set
+func (e *Env) Lookup(name string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	value, ok := e.vars[name]
+	return value, ok
+}
+
+// Set sets a variable
+func (e *Env) Set(name, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.readonly[name] {
+		return fmt.Errorf("%s: %w", name, ErrReadonly)
+	}
+	e.vars[name] = value
+	return nil
+}
+
+// Unset removes a variable
+func (e *Env) Unset(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.readonly[name] {
+		return fmt.Errorf("%s: %w", name, ErrReadonly)
+	}
+	delete(e.vars, name)
+	return nil
+}
+
+// SetReadonly makes the named variables impossible to set or unset
+func (e *Env) SetReadonly(names ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.readonly == nil {
+		e.readonly = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		e.readonly[name] = true
+	}
+}
+
+// Environ returns the variables as sorted KEY=VALUE pairs for child processes
+func (e *Env) Environ() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	environ := make([]string, 0, len(e.vars))
+	for name, value := range e.vars {
+		environ = append(environ, name+"="+value)
+	}
+	slices.Sort(environ)
+	return environ
+}
@@ -0,0 +1,67 @@
+// Package jobs keeps the table of processes started by the shell
+package jobs
+
+import (
+	"slices"
+	"sync"
+
+	"minishell/internal/models"
+)
+
+// Table is a goroutine-safe list of the running processes, grouped into
+// numbered jobs. The zero value is an empty table ready to use.
+type Table struct {
+	mu        sync.Mutex
+	processes []models.Process
+}
+
+// NextJob returns the number the next started job should get
+func (t *Table) NextJob() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	next := 1
+	for _, p := range t.processes {
+		if p.Job >= next {
+			next = p.Job + 1
+		}
+	}
+	return next
+}
+
+// Add registers a started process
+func (t *Table) Add(p models.Process) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.processes = append(t.processes, p)
+}
+
+// Remove forgets a process, usually after it has been waited for
+func (t *Table) Remove(pid int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.processes = slices.DeleteFunc(t.processes, func(p models.Process) bool {
+		return p.PID == pid
+	})
+}
+
+// Processes returns a snapshot of all registered processes
+func (t *Table) Processes() []models.Process {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.processes)
+}
+
+// Job returns the processes belonging to job number id
+func (t *Table) Job(id int) []models.Process {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var procs []models.Process
+	for _, p := range t.processes {
+		if p.Job == id {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
@@ -2,11 +2,16 @@ package models
 
 // Command represents a single shell command with its arguments and I/O redirections.
 type Command struct {
-	Name   string   // Name is the executable or builtin command name.
-	Args   []string // Args contains the arguments passed to the command.
-	Stdin  string   // Stdin is the input file name; empty string means use default input.
-	Stdout string   // Stdout is the output file name; empty string means use default output.
-	Append bool     // Append indicates whether the output should be appended instead of truncated.
+	Name      string     // Name is the executable or builtin command name.
+	Args      []string   // Args contains the arguments passed to the command.
+	Redirects []Redirect // Redirects are applied from left to right before the command runs.
+}
+
+// Redirect represents a redirection of a file descriptor, such as "2>>log" or "2>&1".
+type Redirect struct {
+	FD     int    // FD is the redirected descriptor: 0 for < and <& and 1 for > and >& unless given.
+	Op     string // Op is one of <, >, >>, <& and >&.
+	Target string // Target is a file name, or a descriptor number or "-" to close FD for <& and >&.
 }
 
 // Pipeline represents a sequence of commands connected by pipes.
@@ -25,11 +30,14 @@ const (
 // Job represents a sequence of pipelines combined with conditional operators.
 type Job struct {
 	Pipelines []Pipeline // Pipelines contains the list of pipelines to execute.
-	CondAfter Operator   // CondAfter specifies the operator to apply after this job.
+	CondAfter Operator   // CondAfter is the operator between the previous job and this one; empty for the first.
+	Timed     bool       // Timed reports the time and resources used by the pipelines ("time" prefix).
+	TimePosix bool       // TimePosix prints the time in the POSIX format ("time -p").
 }
 
 // Process represents a running process started by the shell.
 type Process struct {
 	PID int    // PID is the process ID of the running command.
+	Job int    // Job is the number of the job the process belongs to, as used in %N.
 	Cmd string // Cmd is the command string associated with the process.
 }
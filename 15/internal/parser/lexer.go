@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSyntax is returned for malformed command lines
+	ErrSyntax = errors.New("syntax error")
+	// ErrUnbound is returned for an undefined variable when Nounset is set
+	ErrUnbound = errors.New("unbound variable")
+)
+
+// token is an operator or a word of a command line
+type token struct {
+	op      string // op is the operator, or "" for a word
+	word    string // word is the text with quotes removed and variables expanded
+	pattern string // pattern is word with its quoted glob characters escaped
+	glob    bool   // glob reports an unquoted *, ? or [
+	quoted  bool   // quoted reports that part of the word was quoted
+	fd      int    // fd is the descriptor written before a redirection, or -1
+}
+
+// addQuoted appends text that must be taken literally
+func (t *token) addQuoted(s string) {
+	t.word += s
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[\`, s[i]) >= 0 {
+			t.pattern += `\`
+		}
+		t.pattern += s[i : i+1]
+	}
+}
+
+// addUnquoted appends text whose glob characters are patterns
+func (t *token) addUnquoted(s string) {
+	t.word += s
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[':
+			t.glob = true
+		case '\\':
+			t.pattern += `\`
+		}
+		t.pattern += s[i : i+1]
+	}
+}
+
+// lex splits a command line into words and the operators |, &&, ||, and
+// the redirections <, >, >>, <& and >&, which may be prefixed by a
+// descriptor number as in 2>&1. Single quotes keep everything literal,
+// double quotes still expand variables, and a backslash quotes the next
+// character.
+func (p Parser) lex(input string) ([]token, error) {
+	var tokens []token
+	var cur token
+	inWord := false
+	finish := func() {
+		if inWord {
+			tokens = append(tokens, cur)
+		}
+		cur = token{}
+		inWord = false
+	}
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			finish()
+		case c == '<' || c == '>':
+			fd := -1
+			if inWord && !cur.quoted && len(cur.word) == 1 && cur.word[0] >= '0' && cur.word[0] <= '9' {
+				fd = int(cur.word[0] - '0')
+				cur, inWord = token{}, false
+			}
+			finish()
+			op := input[i : i+1]
+			if i+1 < len(input) && (input[i+1] == '&' || (c == '>' && input[i+1] == '>')) {
+				op += input[i+1 : i+2]
+				i++
+			}
+			tokens = append(tokens, token{op: op, fd: fd})
+		case c == '|' || strings.HasPrefix(input[i:], "&&"):
+			finish()
+			op := input[i : i+1]
+			if i+1 < len(input) && input[i+1] == c {
+				op += op
+				i++
+			}
+			tokens = append(tokens, token{op: op})
+		case c == '\'':
+			end := strings.IndexByte(input[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrSyntax)
+			}
+			cur.addQuoted(input[i+1 : i+1+end])
+			cur.quoted, inWord = true, true
+			i += end + 1
+		case c == '"':
+			n, err := p.lexDoubleQuoted(input[i+1:], &cur)
+			if err != nil {
+				return nil, err
+			}
+			cur.quoted, inWord = true, true
+			i += n
+		case c == '\\':
+			if i+1 < len(input) {
+				i++
+			}
+			cur.addQuoted(input[i : i+1])
+			cur.quoted, inWord = true, true
+		case c == '$':
+			value, n, err := p.expand(input[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				cur.addQuoted("$")
+			} else {
+				cur.addUnquoted(value)
+			}
+			inWord = true
+			i += n
+		default:
+			cur.addUnquoted(input[i : i+1])
+			inWord = true
+		}
+	}
+	finish()
+	return tokens, nil
+}
+
+// lexDoubleQuoted reads the text after an opening double quote into cur and
+// returns how many bytes it used, including the closing quote
+func (p Parser) lexDoubleQuoted(input string, cur *token) (int, error) {
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; c {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(input) && strings.IndexByte("$`\"\\\n", input[i+1]) >= 0 {
+				i++
+			}
+			cur.addQuoted(input[i : i+1])
+		case '$':
+			value, n, err := p.expand(input[i+1:])
+			if err != nil {
+				return 0, err
+			}
+			if n == 0 {
+				value = "$"
+			}
+			cur.addQuoted(value)
+			i += n
+		default:
+			cur.addQuoted(input[i : i+1])
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated quote", ErrSyntax)
+}
+
+// expand reads the variable reference after a $: NAME, a special parameter
+// such as ?, or ${NAME} with an optional :-default or -default. It returns
+// the value and how many bytes it used; 0 means the $ is literal.
+func (p Parser) expand(input string) (string, int, error) {
+	if input == "" {
+		return "", 0, nil
+	}
+
+	if input[0] == '{' {
+		end := strings.IndexByte(input, '}')
+		if end < 0 {
+			return "", 0, fmt.Errorf("%w: missing }", ErrSyntax)
+		}
+		value, err := p.expandBraces(input[1:end])
+		return value, end + 1, err
+	}
+
+	if strings.IndexByte("?$#0123456789", input[0]) >= 0 {
+		value, err := p.variable(input[:1])
+		return value, 1, err
+	}
+
+	n := nameLength(input)
+	if n == 0 {
+		return "", 0, nil
+	}
+	value, err := p.variable(input[:n])
+	return value, n, err
+}
+
+// expandBraces expands the inside of ${...}
+func (p Parser) expandBraces(expr string) (string, error) {
+	n := nameLength(expr)
+	if n == 0 && expr != "" && strings.IndexByte("?$#0123456789", expr[0]) >= 0 {
+		n = 1
+	}
+	name, rest := expr[:n], expr[n:]
+	if name == "" {
+		return "", fmt.Errorf("%w: ${%s}: bad substitution", ErrSyntax, expr)
+	}
+
+	switch {
+	case rest == "":
+		return p.variable(name)
+	case strings.HasPrefix(rest, ":-"):
+		value, ok := p.lookup(name)
+		if !ok || value == "" {
+			return rest[2:], nil
+		}
+		return value, nil
+	case strings.HasPrefix(rest, "-"):
+		value, ok := p.lookup(name)
+		if !ok {
+			return rest[1:], nil
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: ${%s}: bad substitution", ErrSyntax, expr)
+}
+
+// variable returns the value of a variable, failing for an undefined one
+// when Nounset is set
+func (p Parser) variable(name string) (string, error) {
+	value, ok := p.lookup(name)
+	if !ok && p.Nounset {
+		return "", fmt.Errorf("%s: %w", name, ErrUnbound)
+	}
+	return value, nil
+}
+
+// nameLength returns the length of the variable name at the start of s
+func nameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -1,126 +1,242 @@
 package parser
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"minishell/internal/models"
 )
 
-// ParseSingleCommand parses a command string into a Command struct
-func ParseSingleCommand(input string) models.Command {
-	tokens := strings.Fields(input)
+// Parser parses command lines, expanding variables through Lookup and
+// glob patterns relative to Dir
+type Parser struct {
+	// Lookup returns the value of a variable; os.LookupEnv is used if nil.
+	Lookup func(name string) (string, bool)
+	// Dir is the directory patterns are matched in; the working directory
+	// of the process is used if empty.
+	Dir string
+	// Nounset makes undefined variables an error, as set -u does.
+	Nounset bool
+}
 
-	cmd := models.Command{}
-	if len(tokens) == 0 {
-		return cmd
-	}
+// ParseSingleCommand parses a command string into a Command struct,
+// expanding variables from the process environment
+func ParseSingleCommand(input string) models.Command {
+	return Parser{}.ParseSingleCommand(input)
+}
 
-	cmd.Name = tokens[0]
-	args := []string{}
+// ParseCommand parses a full input string into Jobs, expanding variables
+// from the process environment
+func ParseCommand(input string) []models.Job {
+	return Parser{}.ParseCommand(input)
+}
 
-	for i := 1; i < len(tokens); i++ {
-		switch tokens[i] {
-		case ">":
-			if i+1 < len(tokens) {
-				cmd.Stdout = tokens[i+1]
-				cmd.Append = false
-				i++
-			}
-		case ">>":
-			if i+1 < len(tokens) {
-				cmd.Stdout = tokens[i+1]
-				cmd.Append = true
-				i++
-			}
-		case "<":
-			if i+1 < len(tokens) {
-				cmd.Stdin = tokens[i+1]
-				i++
-			}
-		default:
-			args = append(args, tokens[i])
-		}
+// ParseSingleCommand parses a command string into a Command struct
+func (p Parser) ParseSingleCommand(input string) models.Command {
+	jobs, err := p.Parse(input)
+	if err != nil || len(jobs) == 0 {
+		return models.Command{}
 	}
+	return jobs[0].Pipelines[0][0]
+}
 
-	cmd.Args = substituteEnv(args)
-	return cmd
+// ParseCommand parses a full input string into Jobs, or returns nil if it
+// is malformed
+func (p Parser) ParseCommand(input string) []models.Job {
+	jobs, err := p.Parse(input)
+	if err != nil {
+		return nil
+	}
+	return jobs
 }
 
-// ParseCommand parses a full input string into Jobs
-func ParseCommand(input string) []models.Job {
-	tokens := splitByOperators(input)
+// Parse parses a command line into jobs separated by && and ||, each a
+// pipeline of commands separated by |
+func (p Parser) Parse(input string) ([]models.Job, error) {
+	tokens, err := p.lex(input)
+	if err != nil {
+		return nil, err
+	}
 
 	jobs := []models.Job{}
-	for _, t := range tokens {
-		pipeParts := strings.Split(strings.TrimSpace(t.Cmd), "|")
-		pipeline := make(models.Pipeline, 0, len(pipeParts))
+	if len(tokens) == 0 {
+		return jobs, nil
+	}
 
-		for _, cmdStr := range pipeParts {
-			cmdStr = strings.TrimSpace(cmdStr)
-			if cmdStr == "" {
-				continue
+	op := models.Operator("")
+	for {
+		end := len(tokens)
+		for i, tok := range tokens {
+			if tok.op == string(models.And) || tok.op == string(models.Or) {
+				end = i
+				break
 			}
-			pipeline = append(pipeline, ParseSingleCommand(cmdStr))
 		}
 
-		job := models.Job{
-			Pipelines: []models.Pipeline{pipeline},
-			CondAfter: t.Op,
+		job, err := p.parseJob(tokens[:end])
+		if err != nil {
+			return nil, err
 		}
+		job.CondAfter = op
 		jobs = append(jobs, job)
+
+		if end == len(tokens) {
+			return jobs, nil
+		}
+		op = models.Operator(tokens[end].op)
+		tokens = tokens[end+1:]
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%w: unexpected end of line after %s", ErrSyntax, op)
+		}
 	}
-	return jobs
 }
 
-type token struct {
-	Cmd string
-	Op  models.Operator
+// parseJob parses a pipeline, optionally prefixed by the "time" keyword
+// and its -p option
+func (p Parser) parseJob(tokens []token) (models.Job, error) {
+	job := models.Job{}
+	if len(tokens) > 0 && tokens[0].op == "" && !tokens[0].quoted && tokens[0].word == "time" {
+		job.Timed = true
+		tokens = tokens[1:]
+		if len(tokens) > 0 && tokens[0].op == "" && !tokens[0].quoted && tokens[0].word == "-p" {
+			job.TimePosix = true
+			tokens = tokens[1:]
+		}
+	}
+
+	pipeline := models.Pipeline{}
+	for {
+		end := len(tokens)
+		for i, tok := range tokens {
+			if tok.op == "|" {
+				end = i
+				break
+			}
+		}
+
+		cmd, err := p.parseSimpleCommand(tokens[:end])
+		if err != nil {
+			return job, err
+		}
+		pipeline = append(pipeline, cmd)
+
+		if end == len(tokens) {
+			break
+		}
+		tokens = tokens[end+1:]
+	}
+
+	job.Pipelines = []models.Pipeline{pipeline}
+	return job, nil
 }
 
-func splitByOperators(input string) []token {
-	result := []token{}
-	cur := ""
-	lastOp := models.Operator("")
-
-	i := 0
-	for i < len(input) {
-		if strings.HasPrefix(input[i:], string(models.And)) {
-			result = append(result, token{Cmd: strings.TrimSpace(cur), Op: lastOp})
-			cur = ""
-			lastOp = models.And
-			i += len(models.And)
-		} else if strings.HasPrefix(input[i:], string(models.Or)) {
-			result = append(result, token{Cmd: strings.TrimSpace(cur), Op: lastOp})
-			cur = ""
-			lastOp = models.Or
-			i += len(models.Or)
-		} else {
-			cur += string(input[i])
-			i++
+// parseSimpleCommand parses the words and redirections of one command
+func (p Parser) parseSimpleCommand(tokens []token) (models.Command, error) {
+	cmd := models.Command{}
+	words := []string{}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.op == "" {
+			words = append(words, p.expandGlob(tok)...)
+			continue
 		}
+
+		r, err := parseRedirect(tok, tokens[i+1:])
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Redirects = append(cmd.Redirects, r)
+		i++
 	}
 
-	if strings.TrimSpace(cur) != "" {
-		result = append(result, token{Cmd: strings.TrimSpace(cur), Op: lastOp})
+	if len(words) == 0 {
+		return cmd, fmt.Errorf("%w: missing command", ErrSyntax)
 	}
+	cmd.Name = words[0]
+	cmd.Args = words[1:]
+	return cmd, nil
+}
 
-	return result
+// parseRedirect builds the redirection of the operator tok to the word
+// that follows it
+func parseRedirect(tok token, rest []token) (models.Redirect, error) {
+	r := models.Redirect{FD: tok.fd, Op: tok.op}
+	switch tok.op {
+	case "<", "<&":
+		if r.FD < 0 {
+			r.FD = 0
+		}
+	case ">", ">>", ">&":
+		if r.FD < 0 {
+			r.FD = 1
+		}
+	default:
+		return r, fmt.Errorf("%w near unexpected token %s", ErrSyntax, tok.op)
+	}
+
+	if len(rest) == 0 || rest[0].op != "" {
+		return r, fmt.Errorf("%w: missing file name after %s", ErrSyntax, tok.op)
+	}
+	r.Target = rest[0].word
+	if strings.HasSuffix(r.Op, "&") && r.Target != "-" {
+		if len(r.Target) != 1 || r.Target[0] < '0' || r.Target[0] > '9' {
+			return r, fmt.Errorf("%w: %s%s: bad file descriptor", ErrSyntax, r.Op, r.Target)
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the value of a variable through p.Lookup or the process
+// environment
+func (p Parser) lookup(name string) (string, bool) {
+	if p.Lookup == nil {
+		return os.LookupEnv(name)
+	}
+	return p.Lookup(name)
 }
 
-func substituteEnv(tokens []string) []string {
-	result := make([]string, len(tokens))
-	for i, tok := range tokens {
-		if strings.HasPrefix(tok, "$") && len(tok) > 1 {
-			val := os.Getenv(tok[1:]) // срезаем $
-			result[i] = val
-		} else {
-			if strings.Contains(tok, "$") {
-				result[i] = os.ExpandEnv(tok)
-			} else {
-				result[i] = tok
+// expandGlob returns the sorted paths an unquoted pattern in the word
+// matches, or the word itself if it is no pattern or matches nothing
+func (p Parser) expandGlob(tok token) []string {
+	if !tok.glob {
+		return []string{tok.word}
+	}
+	matches := p.glob(tok.pattern)
+	if len(matches) == 0 {
+		return []string{tok.word}
+	}
+	return matches
+}
+
+// glob matches pattern in p.Dir, keeping relative patterns relative. Hidden
+// files only match a pattern whose name starts with a dot.
+func (p Parser) glob(pattern string) []string {
+	abs := pattern
+	if !filepath.IsAbs(pattern) && p.Dir != "" {
+		abs = filepath.Join(p.Dir, pattern)
+	}
+	matches, err := filepath.Glob(abs)
+	if err != nil {
+		return nil
+	}
+
+	hidden := strings.HasPrefix(filepath.Base(pattern), ".")
+	result := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if !hidden && strings.HasPrefix(filepath.Base(match), ".") {
+			continue
+		}
+		if abs != pattern {
+			rel, err := filepath.Rel(p.Dir, match)
+			if err != nil {
+				continue
 			}
+			match = rel
 		}
+		result = append(result, match)
 	}
 	return result
 }
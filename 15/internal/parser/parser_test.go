@@ -2,9 +2,11 @@ package parser_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"minishell/internal/models"
 	"minishell/internal/parser"
 )
@@ -27,29 +29,55 @@ func TestParseSingleCommand(t *testing.T) {
 			name:  "output redirection",
 			input: "echo hi > out.txt",
 			expected: models.Command{
-				Name:   "echo",
-				Args:   []string{"hi"},
-				Stdout: "out.txt",
-				Append: false,
+				Name:      "echo",
+				Args:      []string{"hi"},
+				Redirects: []models.Redirect{{FD: 1, Op: ">", Target: "out.txt"}},
 			},
 		},
 		{
 			name:  "append redirection",
 			input: "echo hi >> log.txt",
 			expected: models.Command{
-				Name:   "echo",
-				Args:   []string{"hi"},
-				Stdout: "log.txt",
-				Append: true,
+				Name:      "echo",
+				Args:      []string{"hi"},
+				Redirects: []models.Redirect{{FD: 1, Op: ">>", Target: "log.txt"}},
 			},
 		},
 		{
 			name:  "input redirection",
 			input: "cat < file.txt",
 			expected: models.Command{
-				Name:  "cat",
-				Args:  []string{},
-				Stdin: "file.txt",
+				Name:      "cat",
+				Args:      []string{},
+				Redirects: []models.Redirect{{FD: 0, Op: "<", Target: "file.txt"}},
+			},
+		},
+		{
+			name:  "descriptors",
+			input: "cmd 2>err.log x >&2 3< in 4>&- 2>&1 5>>log",
+			expected: models.Command{
+				Name: "cmd",
+				Args: []string{"x"},
+				Redirects: []models.Redirect{
+					{FD: 2, Op: ">", Target: "err.log"},
+					{FD: 1, Op: ">&", Target: "2"},
+					{FD: 3, Op: "<", Target: "in"},
+					{FD: 4, Op: ">&", Target: "-"},
+					{FD: 2, Op: ">&", Target: "1"},
+					{FD: 5, Op: ">>", Target: "log"},
+				},
+			},
+		},
+		{
+			name:  "numbers that are not descriptors",
+			input: "echo 12>out '2'>out2",
+			expected: models.Command{
+				Name: "echo",
+				Args: []string{"12", "2"},
+				Redirects: []models.Redirect{
+					{FD: 1, Op: ">", Target: "out"},
+					{FD: 1, Op: ">", Target: "out2"},
+				},
 			},
 		},
 		{
@@ -137,3 +165,216 @@ func stringifyCommand(cmd models.Command) string {
 	}
 	return res
 }
+
+func TestTimePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []bool
+		first    string
+	}{
+		{
+			name:     "timed pipeline",
+			input:    "time ls | sort",
+			expected: []bool{true},
+			first:    "ls",
+		},
+		{
+			name:     "only first pipeline timed",
+			input:    "time make && echo done",
+			expected: []bool{true, false},
+			first:    "make",
+		},
+		{
+			name:     "posix format",
+			input:    "time -p ls",
+			expected: []bool{true},
+			first:    "ls",
+		},
+		{
+			name:     "time as an argument",
+			input:    "echo time",
+			expected: []bool{false},
+			first:    "echo time",
+		},
+		{
+			name:     "command starting with time",
+			input:    "timeout 1 ls",
+			expected: []bool{false},
+			first:    "timeout 1 ls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := parser.ParseCommand(tt.input)
+			var timed []bool
+			for _, job := range jobs {
+				timed = append(timed, job.Timed)
+			}
+			assert.Equal(t, tt.expected, timed)
+			assert.Equal(t, tt.first, stringifyCommand(jobs[0].Pipelines[0][0]))
+		})
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", ".hidden.go", "c.txt", "sub/d.go"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "star",
+			input:    "ls *.go",
+			expected: []string{"a.go", "b.go"},
+		},
+		{
+			name:     "hidden files",
+			input:    "ls .*.go",
+			expected: []string{".hidden.go"},
+		},
+		{
+			name:     "subdirectory",
+			input:    "ls */?.go",
+			expected: []string{"sub/d.go"},
+		},
+		{
+			name:     "character class",
+			input:    "ls [bc].*",
+			expected: []string{"b.go", "c.txt"},
+		},
+		{
+			name:     "absolute pattern",
+			input:    "ls " + dir + "/*.txt",
+			expected: []string{filepath.Join(dir, "c.txt")},
+		},
+		{
+			name:     "quoted pattern",
+			input:    `ls '*.go' \*.go`,
+			expected: []string{"*.go", "*.go"},
+		},
+		{
+			name:     "partly quoted pattern",
+			input:    `ls "a"*`,
+			expected: []string{"a.go"},
+		},
+		{
+			name:     "no match is kept",
+			input:    "ls *.md plain",
+			expected: []string{"*.md", "plain"},
+		},
+	}
+
+	p := parser.Parser{Dir: dir}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := p.ParseSingleCommand(tt.input)
+			assert.Equal(t, tt.expected, cmd.Args)
+		})
+	}
+}
+
+func TestParseQuoting(t *testing.T) {
+	vars := map[string]string{"NAME": "a b", "EMPTY": "", "STAR": "*"}
+	p := parser.Parser{
+		Lookup: func(name string) (string, bool) {
+			value, ok := vars[name]
+			return value, ok
+		},
+		Dir: t.TempDir(),
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single quotes",
+			input:    `echo 'a  $NAME | b'`,
+			expected: []string{"echo", "a  $NAME | b"},
+		},
+		{
+			name:     "double quotes",
+			input:    `echo "x $NAME" "${NAME}y" "\$\"\n"`,
+			expected: []string{"echo", "x a b", "a by", `$"\n`},
+		},
+		{
+			name:     "backslash",
+			input:    `echo a\ b \| \'`,
+			expected: []string{"echo", "a b", "|", "'"},
+		},
+		{
+			name:     "adjacent parts",
+			input:    `echo pre'in'"$NAME"post`,
+			expected: []string{"echo", "preina bpost"},
+		},
+		{
+			name:     "empty quotes",
+			input:    `echo '' ""`,
+			expected: []string{"echo", "", ""},
+		},
+		{
+			name:     "defaults",
+			input:    `echo ${EMPTY:-d1} ${EMPTY-d2} ${NOPE-d3}`,
+			expected: []string{"echo", "d1", "", "d3"},
+		},
+		{
+			name:     "literal dollar",
+			input:    `echo $ a$ $-`,
+			expected: []string{"echo", "$", "a$", "$-"},
+		},
+		{
+			name:     "operators without spaces",
+			input:    `echo a|cat>out`,
+			expected: []string{"echo", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := p.Parse(tt.input)
+			require.NoError(t, err)
+			cmd := jobs[0].Pipelines[0][0]
+			assert.Equal(t, tt.expected, append([]string{cmd.Name}, cmd.Args...))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		nounset bool
+		wantErr error
+	}{
+		{name: "unterminated single quote", input: "echo 'a", wantErr: parser.ErrSyntax},
+		{name: "unterminated double quote", input: `echo "a\"`, wantErr: parser.ErrSyntax},
+		{name: "missing command", input: "echo a | | cat", wantErr: parser.ErrSyntax},
+		{name: "trailing operator", input: "echo a &&", wantErr: parser.ErrSyntax},
+		{name: "missing file", input: "echo a >", wantErr: parser.ErrSyntax},
+		{name: "bad descriptor", input: "echo a >&x", wantErr: parser.ErrSyntax},
+		{name: "bad substitution", input: "echo ${A+b}", wantErr: parser.ErrSyntax},
+		{name: "unbound variable", input: "echo $NOPE_NOT_SET", nounset: true, wantErr: parser.ErrUnbound},
+		{name: "unbound with default", input: "echo ${NOPE_NOT_SET:-x}", nounset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parser{Nounset: tt.nounset}.Parse(tt.input)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
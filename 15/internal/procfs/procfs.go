@@ -0,0 +1,46 @@
+// Package procfs reads information about running processes from /proc
+package procfs
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned on platforms without a Linux-style /proc
+var ErrUnsupported = errors.New("procfs: not supported on this platform")
+
+// FS is a proc filesystem mounted at a directory
+type FS struct {
+	root string
+}
+
+// Default is the proc filesystem of the system
+var Default = New("/proc")
+
+// New returns the proc filesystem mounted at root
+func New(root string) FS {
+	return FS{root: root}
+}
+
+// Read returns the information about a single process
+func Read(pid int) (Proc, error) {
+	return Default.Read(pid)
+}
+
+// All returns the information about every visible process
+func All() ([]Proc, error) {
+	return Default.All()
+}
+
+// Proc describes a single process.
+type Proc struct {
+	PID     int           // PID is the process ID.
+	PPID    int           // PPID is the parent process ID.
+	PGID    int           // PGID is the process group ID.
+	State   string        // State is the one-letter scheduler state (R, S, D, Z, T, ...).
+	UID     int           // UID is the real user ID of the owner.
+	Comm    string        // Comm is the executable name, truncated by the kernel.
+	Cmdline []string      // Cmdline is the full argument vector; empty for kernel threads.
+	CPUTime time.Duration // CPUTime is the user plus system time consumed so far.
+	RSS     int64         // RSS is the resident set size in bytes.
+}
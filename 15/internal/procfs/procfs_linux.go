@@ -0,0 +1,109 @@
+//go:build linux
+
+package procfs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of the times in /proc/[pid]/stat.
+// It is 100 on every Linux architecture Go supports.
+const clockTicks = 100
+
+// Read returns the information about a single process
+func (fs FS) Read(pid int) (Proc, error) {
+	dir := filepath.Join(fs.root, strconv.Itoa(pid))
+
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Proc{}, err
+	}
+	p, err := parseStat(data)
+	if err != nil {
+		return Proc{}, fmt.Errorf("procfs: %s/stat: %w", dir, err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return Proc{}, err
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		p.UID = int(st.Uid)
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err == nil {
+		cmdline = bytes.TrimRight(cmdline, "\x00")
+		if len(cmdline) > 0 {
+			p.Cmdline = strings.Split(string(cmdline), "\x00")
+		}
+	}
+
+	return p, nil
+}
+
+// All returns the information about every visible process
+func (fs FS) All() ([]Proc, error) {
+	entries, err := os.ReadDir(fs.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []Proc
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		p, err := fs.Read(pid)
+		if err != nil {
+			// the process exited while we were reading the directory
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// parseStat parses the contents of /proc/[pid]/stat, see proc(5)
+func parseStat(data []byte) (Proc, error) {
+	// comm is enclosed in parentheses and may itself contain spaces and ')'
+	open := bytes.IndexByte(data, '(')
+	closing := bytes.LastIndexByte(data, ')')
+	if open < 0 || closing < open {
+		return Proc{}, fmt.Errorf("malformed stat line")
+	}
+
+	pid, err := strconv.Atoi(string(bytes.TrimSpace(data[:open])))
+	if err != nil {
+		return Proc{}, fmt.Errorf("invalid pid: %w", err)
+	}
+
+	// fields after comm start with field 3 (state)
+	fields := strings.Fields(string(data[closing+1:]))
+	if len(fields) < 22 {
+		return Proc{}, fmt.Errorf("too few fields")
+	}
+	field := func(n int) int64 {
+		v, _ := strconv.ParseInt(fields[n-3], 10, 64)
+		return v
+	}
+
+	ticks := field(14) + field(15) // utime + stime
+	return Proc{
+		PID:     pid,
+		PPID:    int(field(4)),
+		PGID:    int(field(5)),
+		State:   fields[0],
+		Comm:    string(data[open+1 : closing]),
+		CPUTime: time.Duration(ticks) * time.Second / clockTicks,
+		RSS:     field(24) * int64(os.Getpagesize()),
+	}, nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package procfs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/procfs"
+)
+
+func TestRead(t *testing.T) {
+	p, err := procfs.Read(os.Getpid())
+	require.NoError(t, err)
+
+	assert.Equal(t, os.Getpid(), p.PID)
+	assert.Equal(t, os.Getppid(), p.PPID)
+	assert.Equal(t, os.Getuid(), p.UID)
+	assert.NotEmpty(t, p.State)
+	assert.NotEmpty(t, p.Comm)
+	assert.Equal(t, os.Args, p.Cmdline)
+	assert.Positive(t, p.RSS)
+}
+
+func TestAll(t *testing.T) {
+	procs, err := procfs.All()
+	require.NoError(t, err)
+
+	var found bool
+	for _, p := range procs {
+		if p.PID == os.Getpid() {
+			found = true
+		}
+	}
+	assert.True(t, found, "own process not listed")
+}
+
+func TestReadMissing(t *testing.T) {
+	_, err := procfs.Read(-1)
+	assert.Error(t, err)
+}
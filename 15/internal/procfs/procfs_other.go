@@ -0,0 +1,13 @@
+//go:build !linux
+
+package procfs
+
+// Read returns the information about a single process
+func (fs FS) Read(pid int) (Proc, error) {
+	return Proc{}, ErrUnsupported
+}
+
+// All returns the information about every visible process
+func (fs FS) All() ([]Proc, error) {
+	return nil, ErrUnsupported
+}
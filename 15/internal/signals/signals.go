@@ -0,0 +1,67 @@
+// Package signals converts between signal names and numbers
+package signals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Parse accepts a signal as a number or as a name with or without the SIG
+// prefix, in any case: "15", "TERM", "SIGTERM" and "term" are all SIGTERM.
+func Parse(s string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > maxSignal {
+			return 0, fmt.Errorf("invalid signal number: %s", s)
+		}
+		return syscall.Signal(n), nil
+	}
+
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	if sig, ok := lookupName(name); ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("invalid signal specification: %s", s)
+}
+
+// Name returns the name of sig without the SIG prefix, e.g. "TERM"
+func Name(sig syscall.Signal) string {
+	for _, e := range table {
+		if e.sig == sig {
+			return e.name
+		}
+	}
+	if name, ok := realtimeName(sig); ok {
+		return name
+	}
+	return strconv.Itoa(int(sig))
+}
+
+// List returns every known signal in ascending order
+func List() []syscall.Signal {
+	sigs := make([]syscall.Signal, 0, len(table))
+	for _, e := range table {
+		sigs = append(sigs, e.sig)
+	}
+	return append(sigs, realtimeSignals()...)
+}
+
+type entry struct {
+	name string
+	sig  syscall.Signal
+}
+
+func lookupName(name string) (syscall.Signal, bool) {
+	for _, e := range table {
+		if e.name == name {
+			return e.sig, true
+		}
+	}
+	for _, e := range aliases {
+		if e.name == name {
+			return e.sig, true
+		}
+	}
+	return parseRealtime(name)
+}
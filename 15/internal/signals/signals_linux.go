@@ -0,0 +1,116 @@
+//go:build linux
+
+package signals
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	maxSignal = 64
+	sigRTMIN  = syscall.Signal(34)
+	sigRTMAX  = syscall.Signal(64)
+)
+
+// table lists the standard signals in signal(7) order
+var table = []entry{
+	{"HUP", syscall.SIGHUP},
+	{"INT", syscall.SIGINT},
+	{"QUIT", syscall.SIGQUIT},
+	{"ILL", syscall.SIGILL},
+	{"TRAP", syscall.SIGTRAP},
+	{"ABRT", syscall.SIGABRT},
+	{"BUS", syscall.SIGBUS},
+	{"FPE", syscall.SIGFPE},
+	{"KILL", syscall.SIGKILL},
+	{"USR1", syscall.SIGUSR1},
+	{"SEGV", syscall.SIGSEGV},
+	{"USR2", syscall.SIGUSR2},
+	{"PIPE", syscall.SIGPIPE},
+	{"ALRM", syscall.SIGALRM},
+	{"TERM", syscall.SIGTERM},
+	{"STKFLT", syscall.SIGSTKFLT},
+	{"CHLD", syscall.SIGCHLD},
+	{"CONT", syscall.SIGCONT},
+	{"STOP", syscall.SIGSTOP},
+	{"TSTP", syscall.SIGTSTP},
+	{"TTIN", syscall.SIGTTIN},
+	{"TTOU", syscall.SIGTTOU},
+	{"URG", syscall.SIGURG},
+	{"XCPU", syscall.SIGXCPU},
+	{"XFSZ", syscall.SIGXFSZ},
+	{"VTALRM", syscall.SIGVTALRM},
+	{"PROF", syscall.SIGPROF},
+	{"WINCH", syscall.SIGWINCH},
+	{"IO", syscall.SIGIO},
+	{"PWR", syscall.SIGPWR},
+	{"SYS", syscall.SIGSYS},
+}
+
+var aliases = []entry{
+	{"IOT", syscall.SIGIOT},
+	{"CLD", syscall.SIGCHLD},
+	{"POLL", syscall.SIGPOLL},
+	{"UNUSED", syscall.SIGUNUSED},
+}
+
+// realtimeSignals returns SIGRTMIN..SIGRTMAX
+func realtimeSignals() []syscall.Signal {
+	var sigs []syscall.Signal
+	for sig := sigRTMIN; sig <= sigRTMAX; sig++ {
+		sigs = append(sigs, sig)
+	}
+	return sigs
+}
+
+// realtimeName names real-time signals the way bash does: RTMIN+n for the
+// lower half and RTMAX-n for the upper one
+func realtimeName(sig syscall.Signal) (string, bool) {
+	switch {
+	case sig < sigRTMIN || sig > sigRTMAX:
+		return "", false
+	case sig == sigRTMIN:
+		return "RTMIN", true
+	case sig == sigRTMAX:
+		return "RTMAX", true
+	case sig-sigRTMIN <= (sigRTMAX-sigRTMIN)/2:
+		return "RTMIN+" + strconv.Itoa(int(sig-sigRTMIN)), true
+	default:
+		return "RTMAX-" + strconv.Itoa(int(sigRTMAX-sig)), true
+	}
+}
+
+// parseRealtime parses RTMIN, RTMAX, RTMIN+n and RTMAX-n
+func parseRealtime(name string) (syscall.Signal, bool) {
+	base, offset, sign := name, "", 0
+	if i := strings.IndexAny(name, "+-"); i >= 0 {
+		base, offset = name[:i], name[i+1:]
+		sign = 1
+		if name[i] == '-' {
+			sign = -1
+		}
+	}
+
+	var sig syscall.Signal
+	switch base {
+	case "RTMIN":
+		sig = sigRTMIN
+	case "RTMAX":
+		sig = sigRTMAX
+	default:
+		return 0, false
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return 0, false
+		}
+		sig += syscall.Signal(sign * n)
+	}
+	if sig < sigRTMIN || sig > sigRTMAX {
+		return 0, false
+	}
+	return sig, true
+}
@@ -0,0 +1,50 @@
+package signals_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/internal/signals"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    syscall.Signal
+		wantErr bool
+	}{
+		{name: "number", input: "9", want: syscall.SIGKILL},
+		{name: "zero", input: "0", want: 0},
+		{name: "short name", input: "TERM", want: syscall.SIGTERM},
+		{name: "full name", input: "SIGHUP", want: syscall.SIGHUP},
+		{name: "lower case", input: "int", want: syscall.SIGINT},
+		{name: "alias", input: "IOT", want: syscall.SIGABRT},
+		{name: "unknown name", input: "FOO", wantErr: true},
+		{name: "negative number", input: "-1", wantErr: true},
+		{name: "too large", input: "1000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signals.Parse(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNameRoundTrip(t *testing.T) {
+	for _, sig := range signals.List() {
+		got, err := signals.Parse(signals.Name(sig))
+		require.NoError(t, err)
+		assert.Equal(t, sig, got)
+	}
+}
@@ -0,0 +1,180 @@
+package shell_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/shell"
+)
+
+// conformanceInputs are the files the conformance cases read
+var conformanceInputs = map[string]string{
+	"words.txt":    "alpha\nBeta\ngamma\n1.5\nalphabet\ndelta\n",
+	"table.txt":    "a\tb\tc\nd\te\tf\nnodelim\n",
+	"colon.txt":    "x:y:z\na:\n",
+	"fruits.txt":   "banana 3\napple 10\ncherry 2\napple 10\n",
+	"keys.txt":     "a:z\nb:y\nc:x\n",
+	"nums.txt":     "10\n9\n100\n",
+	"months.txt":   "Mar\nJan\nFeb\n",
+	"sizes.txt":    "1K\n512\n2M\n",
+	"versions.txt": "app-1.10.2\napp-1.9.0\napp-1.9.0-rc1\napp-1.9.0~rc1\n",
+	"floats.txt":   "1e3\n0x1p4\n-inf\n2.5\n",
+	"sorted.txt":   "a\nc\nc\n",
+	"sorted2.txt":  "b\nc\nd\n",
+	"empty.txt":    "",
+	"zero.txt":     "b\x00a\nz\x00",
+	"list0.txt":    "sorted2.txt\x00sorted.txt\x00",
+	"names.txt":    "Zebra\nÄpfel\napple\nApfel\nzebra\n",
+}
+
+// buildCLIs builds mygrep, mycut and mysort into a temporary directory and
+// returns the paths of the binaries by the name of their command
+func buildCLIs(t *testing.T) map[string]string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	bin := t.TempDir()
+	paths := make(map[string]string)
+	for name, pkg := range map[string]string{"grep": "mygrep/cmd", "cut": "mycut/cmd", "sort": "mysort/cmd"} {
+		path := filepath.Join(bin, name)
+		out, err := exec.Command("go", "build", "-o", path, pkg).CombinedOutput()
+		require.NoError(t, err, string(out))
+		paths[name] = path
+	}
+	return paths
+}
+
+// TestConformance runs every case with the grep, cut and sort builtins and
+// with the standalone CLIs, which share their packages, to check that both
+// entry points print the same
+func TestConformance(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantOut    string
+		wantStatus int
+		wantErr    string // on stderr after "cmd: " when the status is not 0
+		quiet      bool   // the command prints no message
+	}{
+		{name: "grep", args: []string{"grep", "alpha"}, stdin: "words.txt", wantOut: "alpha\nalphabet\n"},
+		{name: "grep ignore case", args: []string{"grep", "-i", "beta"}, stdin: "words.txt", wantOut: "Beta\n"},
+		{name: "grep invert", args: []string{"grep", "-v", "a$"}, stdin: "words.txt", wantOut: "1.5\nalphabet\n"},
+		{name: "grep count", args: []string{"grep", "-c", "a"}, stdin: "words.txt", wantOut: "5\n"},
+		{name: "grep line numbers", args: []string{"grep", "-n", "ph"}, stdin: "words.txt", wantOut: "1:alpha\n5:alphabet\n"},
+		{name: "grep fixed", args: []string{"grep", "-F", "."}, stdin: "words.txt", wantOut: "1.5\n"},
+		{name: "grep after", args: []string{"grep", "-A", "1", "gamma"}, stdin: "words.txt", wantOut: "gamma\n1.5\n"},
+		{name: "grep before", args: []string{"grep", "-B", "1", "delta"}, stdin: "words.txt", wantOut: "alphabet\ndelta\n"},
+		{name: "grep context", args: []string{"grep", "-C", "1", "1.5"}, stdin: "words.txt", wantOut: "gamma\n1.5\nalphabet\n"},
+		{name: "grep pattern option", args: []string{"grep", "-e", "Beta"}, stdin: "words.txt", wantOut: "Beta\n"},
+		{name: "grep no match", args: []string{"grep", "zzz"}, stdin: "words.txt", wantOut: ""},
+		{name: "grep bundled flags", args: []string{"grep", "-in", "BETA"}, stdin: "words.txt", wantOut: "2:Beta\n"},
+		{name: "grep long option", args: []string{"grep", "--count", "a"}, stdin: "words.txt", wantOut: "5\n"},
+		{name: "grep attached value", args: []string{"grep", "-A1", "gamma"}, stdin: "words.txt", wantOut: "gamma\n1.5\n"},
+		{name: "grep missing value", args: []string{"grep", "gamma", "-A"}, stdin: "words.txt", wantStatus: 2},
+		{name: "grep unknown option", args: []string{"grep", "-z", "gamma"}, stdin: "words.txt", wantStatus: 2},
+		{name: "grep file", args: []string{"grep", "gamma", "words.txt"}, wantOut: "gamma\n"},
+		{name: "grep files", args: []string{"grep", "-c", "a", "words.txt", "colon.txt"}, wantOut: "words.txt:5\ncolon.txt:1\n"},
+		{name: "grep files and stdin", args: []string{"grep", "ph", "-", "words.txt"}, stdin: "words.txt", wantOut: "(standard input):alpha\n(standard input):alphabet\nwords.txt:alpha\nwords.txt:alphabet\n"},
+		{name: "grep missing file", args: []string{"grep", "ph", "nosuch.txt", "words.txt"}, wantOut: "words.txt:alpha\nwords.txt:alphabet\n", wantStatus: 2, wantErr: "nosuch.txt: no such file or directory"},
+		{name: "grep directory", args: []string{"grep", "ph", "."}, wantStatus: 2, wantErr: ".: is a directory"},
+		{name: "cut", args: []string{"cut", "-f", "2"}, stdin: "table.txt", wantOut: "b\ne\n"},
+		{name: "cut list", args: []string{"cut", "-f", "1,3"}, stdin: "table.txt", wantOut: "a\tc\nd\tf\nnodelim\n"},
+		{name: "cut separated", args: []string{"cut", "-s", "-f", "1"}, stdin: "table.txt", wantOut: "a\nd\n"},
+		{name: "cut delimiter", args: []string{"cut", "-d", ":", "-f", "2"}, stdin: "colon.txt", wantOut: "y\n\n"},
+		{name: "cut attached values", args: []string{"cut", "-d:", "-f1"}, stdin: "colon.txt", wantOut: "x\na\n"},
+		{name: "cut missing fields", args: []string{"cut", "-s"}, stdin: "table.txt", wantStatus: 2},
+		{name: "cut file", args: []string{"cut", "-f", "2-3", "table.txt"}, wantOut: "b\tc\ne\tf\n"},
+		{name: "cut files", args: []string{"cut", "-d", ":", "-f", "1", "colon.txt", "keys.txt"}, wantOut: "x\na\na\nb\nc\n"},
+		{name: "cut missing file", args: []string{"cut", "-d", ":", "-f", "2", "colon.txt", "nosuch.txt", "keys.txt"}, wantOut: "y\n\nz\ny\nx\n", wantStatus: 1, wantErr: "nosuch.txt: no such file or directory"},
+		{name: "sort", args: []string{"sort"}, stdin: "fruits.txt", wantOut: "apple 10\napple 10\nbanana 3\ncherry 2\n"},
+		{name: "sort reverse", args: []string{"sort", "-r"}, stdin: "fruits.txt", wantOut: "cherry 2\nbanana 3\napple 10\napple 10\n"},
+		{name: "sort unique", args: []string{"sort", "-u"}, stdin: "fruits.txt", wantOut: "apple 10\nbanana 3\ncherry 2\n"},
+		{name: "sort blank separated key", args: []string{"sort", "-n", "-k", "2"}, stdin: "fruits.txt", wantOut: "cherry 2\nbanana 3\napple 10\napple 10\n"},
+		{name: "sort numeric", args: []string{"sort", "-n"}, stdin: "nums.txt", wantOut: "9\n10\n100\n"},
+		{name: "sort delimiter", args: []string{"sort", "-t", ":", "-k", "2"}, stdin: "keys.txt", wantOut: "c:x\nb:y\na:z\n"},
+		{name: "sort old delimiter option", args: []string{"sort", "-N", ":", "-k", "2"}, stdin: "keys.txt", wantOut: "c:x\nb:y\na:z\n"},
+		{name: "sort keys", args: []string{"sort", "-k", "2,2nr", "-k", "1,1"}, stdin: "fruits.txt", wantOut: "apple 10\napple 10\nbanana 3\ncherry 2\n"},
+		{name: "sort months", args: []string{"sort", "-M"}, stdin: "months.txt", wantOut: "Jan\nFeb\nMar\n"},
+		{name: "sort human", args: []string{"sort", "-n", "-h"}, stdin: "sizes.txt", wantOut: "512\n1K\n2M\n"},
+		{name: "sort human alone", args: []string{"sort", "-h"}, stdin: "sizes.txt", wantOut: "512\n1K\n2M\n"},
+		{name: "sort bundled flags", args: []string{"sort", "-rn"}, stdin: "nums.txt", wantOut: "100\n10\n9\n"},
+		{name: "sort end of options", args: []string{"sort", "-r", "--", "nums.txt"}, wantOut: "9\n100\n10\n"},
+		{name: "sort in temporary files", args: []string{"sort", "-n", "-S", "1b", "-T", ".", "--parallel=2"}, stdin: "fruits.txt", wantOut: "apple 10\napple 10\nbanana 3\ncherry 2\n"},
+		{name: "sort version", args: []string{"sort", "-V"}, stdin: "versions.txt", wantOut: "app-1.9.0~rc1\napp-1.9.0\napp-1.9.0-rc1\napp-1.10.2\n"},
+		{name: "sort general numeric", args: []string{"sort", "-g"}, stdin: "floats.txt", wantOut: "-inf\n2.5\n0x1p4\n1e3\n"},
+		{name: "sort fold case", args: []string{"sort", "-f"}, stdin: "names.txt", wantOut: "Apfel\napple\nZebra\nzebra\nÄpfel\n"},
+		{name: "sort locale", args: []string{"sort", "--locale=de"}, stdin: "names.txt", wantOut: "Apfel\nÄpfel\napple\nzebra\nZebra\n"},
+		{name: "sort unsupported locale", args: []string{"sort", "--locale=xx"}, stdin: "names.txt", wantStatus: 2},
+		{name: "sort check", args: []string{"sort", "-c"}, stdin: "nums.txt", wantStatus: 1, wantErr: "-:3: disorder: 100"},
+		{name: "sort check file", args: []string{"sort", "-cu", "sorted.txt"}, wantStatus: 1, wantErr: "sorted.txt:3: disorder: c"},
+		{name: "sort check sorted", args: []string{"sort", "-c", "sorted.txt"}},
+		{name: "sort quiet check", args: []string{"sort", "-C"}, stdin: "nums.txt", wantStatus: 1, quiet: true},
+		{name: "sort check of two files", args: []string{"sort", "-c", "sorted.txt", "nums.txt"}, wantStatus: 2},
+		{name: "sort merge", args: []string{"sort", "-m", "sorted.txt", "sorted2.txt"}, wantOut: "a\nb\nc\nc\nc\nd\n"},
+		{name: "sort merge unique", args: []string{"sort", "-mu", "sorted.txt", "sorted2.txt"}, wantOut: "a\nb\nc\nd\n"},
+		{name: "sort files and stdin", args: []string{"sort", "sorted2.txt", "-"}, stdin: "nums.txt", wantOut: "10\n100\n9\nb\nc\nd\n"},
+		{name: "sort missing file", args: []string{"sort", "sorted.txt", "nosuch.txt"}, wantStatus: 2},
+		{name: "sort zero terminated", args: []string{"sort", "-z"}, stdin: "zero.txt", wantOut: "a\nz\x00b\x00"},
+		{name: "sort files0-from", args: []string{"sort", "--files0-from=list0.txt"}, wantOut: "a\nb\nc\nc\nc\nd\n"},
+		{name: "sort files0-from and operands", args: []string{"sort", "--files0-from=list0.txt", "nums.txt"}, wantStatus: 2},
+		{name: "sort empty files0-from", args: []string{"sort", "--files0-from=-"}, stdin: "empty.txt", wantStatus: 2, wantErr: "no input from '-'"},
+		{name: "sort check with output", args: []string{"sort", "-c", "-o", "x.txt", "nums.txt"}, wantStatus: 2},
+		{name: "sort invalid buffer size", args: []string{"sort", "-S", "lots"}, stdin: "nums.txt", wantStatus: 2},
+		{name: "sort invalid key", args: []string{"sort", "-k", "x"}, stdin: "nums.txt", wantStatus: 2},
+		{name: "sort file", args: []string{"sort", "-r", "nums.txt"}, wantOut: "9\n100\n10\n"},
+	}
+
+	clis := buildCLIs(t)
+	dir := t.TempDir()
+	for name, content := range conformanceInputs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := tt.args[0]
+			for _, arg := range tt.args[1:] {
+				script += " '" + arg + "'"
+			}
+			if tt.stdin != "" {
+				script += " < " + tt.stdin
+			}
+
+			sh, stdout, stderr := newShell(t, shell.WithDir(dir))
+			status, _ := sh.Exec(context.Background(), script)
+			assert.Equal(t, tt.wantStatus, status, stderr.String())
+			assert.Equal(t, tt.wantOut, stdout.String(), "builtin")
+			checkStderr := func(stderr string) {
+				switch {
+				case tt.quiet:
+					assert.NotContains(t, stderr, tt.args[0]+": ")
+				case tt.wantStatus != 0:
+					assert.Contains(t, stderr, tt.args[0]+": "+tt.wantErr)
+				}
+			}
+			checkStderr(stderr.String())
+
+			cmd := exec.Command(clis[tt.args[0]], tt.args[1:]...)
+			cmd.Dir = dir
+			if tt.stdin != "" {
+				cmd.Stdin = strings.NewReader(conformanceInputs[tt.stdin])
+			}
+			var cliStderr strings.Builder
+			cmd.Stderr = &cliStderr
+			out, _ := cmd.Output()
+			assert.Equal(t, tt.wantOut, string(out), "CLI")
+			assert.Equal(t, tt.wantStatus, cmd.ProcessState.ExitCode(), "CLI status")
+			checkStderr(cliStderr.String())
+		})
+	}
+}
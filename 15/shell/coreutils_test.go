@@ -0,0 +1,145 @@
+package shell_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/shell"
+)
+
+// coreutilsFiles are the files the coreutils tests work on
+var coreutilsFiles = map[string]string{
+	"a.txt":    "one two\nthree\nfour  five six\n",
+	"b.txt":    "x\ny",
+	"dup.txt":  "a\na\nb\nA\nc\nc\nc\n",
+	"nums.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n",
+}
+
+// hasGNUCoreutils reports whether the system has GNU coreutils to compare
+// the builtins with
+func hasGNUCoreutils() bool {
+	out, err := exec.Command("cat", "--version").Output()
+	return err == nil && strings.Contains(string(out), "GNU coreutils")
+}
+
+// TestExecCoreutils runs every script with the builtins and, when they are
+// installed, with GNU coreutils under sh, to check that the expected output
+// is the one of GNU
+func TestExecCoreutils(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{name: "cat", script: "cat a.txt b.txt", wantOut: "one two\nthree\nfour  five six\nx\ny"},
+		{name: "cat numbered", script: "cat -n b.txt a.txt", wantOut: "     1\tx\n     2\tyone two\n     3\tthree\n     4\tfour  five six\n"},
+		{name: "cat missing file", script: "cat nope.txt a.txt", wantOut: "one two\nthree\nfour  five six\n", wantStatus: 1},
+		{name: "head lines", script: "head -n 3 nums.txt", wantOut: "1\n2\n3\n"},
+		{name: "head old form", script: "head -2 a.txt", wantOut: "one two\nthree\n"},
+		{name: "head bytes", script: "head -c 5 nums.txt", wantOut: "1\n2\n3"},
+		{name: "head all but lines", script: "head -n -17 nums.txt", wantOut: "1\n2\n3\n"},
+		{name: "head all but bytes", script: "head -c -40 nums.txt", wantOut: "1\n2\n3\n4\n5\n6"},
+		{name: "head headers", script: "head -n 1 a.txt b.txt", wantOut: "==> a.txt <==\none two\n\n==> b.txt <==\nx\n"},
+		{name: "head quiet", script: "head -q -n 1 a.txt b.txt", wantOut: "one two\nx\n"},
+		{name: "head stdin", script: "cat nums.txt | head -n 2", wantOut: "1\n2\n"},
+		{name: "tail lines", script: "tail -n 2 nums.txt", wantOut: "19\n20\n"},
+		{name: "tail from line", script: "tail -n +19 nums.txt", wantOut: "19\n20\n"},
+		{name: "tail bytes", script: "tail -c 4 nums.txt", wantOut: "\n20\n"},
+		{name: "tail headers", script: "tail -n 1 a.txt - b.txt < b.txt", wantOut: "==> a.txt <==\nfour  five six\n\n==> standard input <==\ny\n==> b.txt <==\ny"},
+		{name: "tail no final newline", script: "tail b.txt", wantOut: "x\ny"},
+		{name: "wc", script: "wc a.txt", wantOut: " 3  6 29 a.txt\n"},
+		{name: "wc total", script: "wc -l a.txt b.txt", wantOut: " 3 a.txt\n 1 b.txt\n 4 total\n"},
+		{name: "wc single count", script: "wc -w < a.txt", wantOut: "6\n"},
+		{name: "wc pipe", script: "cat a.txt | wc", wantOut: "      3       6      29\n"},
+		{name: "wc pipe and file", script: "cat a.txt | wc -lc - b.txt", wantOut: "      3      29 -\n      1       3 b.txt\n      4      32 total\n"},
+		{name: "wc characters", script: "printf 'é\\n' | wc -m", wantOut: "2\n"},
+		{name: "tee", script: "echo hi | tee t1 t2 && cat t1 t2", wantOut: "hi\nhi\nhi\n"},
+		{name: "tee append", script: "echo hi | tee t1 > /dev/null\necho more | tee -a t1\ncat t1", wantOut: "more\nhi\nmore\n"},
+		{name: "uniq", script: "uniq dup.txt", wantOut: "a\nb\nA\nc\n"},
+		{name: "uniq count", script: "uniq -c dup.txt", wantOut: "      2 a\n      1 b\n      1 A\n      3 c\n"},
+		{name: "uniq repeated", script: "uniq -d dup.txt", wantOut: "a\nc\n"},
+		{name: "uniq unique", script: "uniq -u dup.txt", wantOut: "b\nA\n"},
+		{name: "uniq ignore case", script: "uniq -ic dup.txt", wantOut: "      2 a\n      1 b\n      1 A\n      3 c\n"},
+		{name: "uniq output file", script: "uniq dup.txt out.txt && cat out.txt", wantOut: "a\nb\nA\nc\n"},
+		{name: "tr ranges", script: "tr a-z A-Z < a.txt", wantOut: "ONE TWO\nTHREE\nFOUR  FIVE SIX\n"},
+		{name: "tr delete", script: "tr -d aeiou < a.txt", wantOut: "n tw\nthr\nfr  fv sx\n"},
+		{name: "tr squeeze", script: "tr -s ' ' < a.txt", wantOut: "one two\nthree\nfour five six\n"},
+		{name: "tr classes", script: "tr '[:lower:]' '[:upper:]' < b.txt", wantOut: "X\nY"},
+		{name: "tr complement", script: "tr -c 'a-z\\n' _ < a.txt", wantOut: "one_two\nthree\nfour__five_six\n"},
+		{name: "tr escapes", script: "printf 'a\\tb\\n' | tr '\\t' ' '", wantOut: "a b\n"},
+		{name: "tr short set", script: "echo abc | tr abc x", wantOut: "xxx\n"},
+		{name: "tr translate and squeeze", script: "echo hello | tr -s l", wantOut: "helo\n"},
+		{name: "tr delete and squeeze", script: "tr -ds e o < a.txt", wantOut: "on two\nthr\nfour  fiv six\n"},
+		{name: "tr reversed range", script: "tr z-a x", wantStatus: 1},
+		{name: "tr fill", script: "echo hello | tr o '[x*]'", wantOut: "hellx\n"},
+		{name: "tr fill between", script: "echo abcde | tr a-e 'x[y*]z'", wantOut: "xyyyz\n"},
+		{name: "tr repeat", script: "echo abc | tr a-c '[x*02]z'", wantOut: "xxz\n"},
+		{name: "tr repeat in set1", script: "echo 'a[*2]b' | tr '[a*2]' x", wantOut: "x[*2]b\n"},
+		{name: "tr equivalence class", script: "echo aab | tr '[=a=]' x", wantOut: "xxb\n"},
+		{name: "tr fill in set1", script: "tr '[a*]' x", wantStatus: 1},
+		{name: "tr two fills", script: "tr a-c '[x*]y[z*]'", wantStatus: 1},
+		{name: "tr bad repeat count", script: "tr a-c '[x*9z]'", wantStatus: 1},
+	}
+
+	gnu := hasGNUCoreutils()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range coreutilsFiles {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			sh, stdout, _ := newShell(t, shell.WithDir(dir))
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+
+			if !gnu {
+				return
+			}
+			gnuDir := t.TempDir()
+			for name, content := range coreutilsFiles {
+				require.NoError(t, os.WriteFile(filepath.Join(gnuDir, name), []byte(content), 0o644))
+			}
+			cmd := exec.Command("sh", "-c", tt.script)
+			cmd.Dir = gnuDir
+			cmd.Env = append(os.Environ(), "LC_ALL=C.UTF-8")
+			out, _ := cmd.Output()
+			assert.Equal(t, tt.wantOut, string(out), "GNU output")
+			assert.Equal(t, tt.wantStatus, cmd.ProcessState.ExitCode(), "GNU status")
+		})
+	}
+}
+
+func TestExecTailFollow(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log.txt")
+	require.NoError(t, os.WriteFile(log, []byte("a\nb\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(250 * time.Millisecond)
+		f, err := os.OpenFile(log, os.O_WRONLY|os.O_APPEND, 0)
+		if err == nil {
+			f.WriteString("c\n")
+			f.Close()
+		}
+		time.Sleep(250 * time.Millisecond)
+		cancel()
+	}()
+
+	sh, stdout, stderr := newShell(t, shell.WithDir(dir))
+	_, err := sh.Exec(ctx, "tail -n 1 -f log.txt")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "b\nc\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
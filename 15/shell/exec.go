@@ -0,0 +1,278 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"minishell/internal/builtins"
+	"minishell/internal/models"
+)
+
+// HandlerContext describes the environment an external command runs in
+type HandlerContext struct {
+	Dir    string    // Dir is the working directory of the shell.
+	Env    []string  // Env holds the shell variables as KEY=VALUE pairs.
+	Stdin  io.Reader // Stdin is the input of the command.
+	Stdout io.Writer // Stdout is where the command writes its output.
+	Stderr io.Writer // Stderr is where the command writes its errors.
+
+	// ExtraFiles are the descriptors from 3 on, as in exec.Cmd; entries
+	// of closed descriptors are nil.
+	ExtraFiles []*os.File
+}
+
+// ExecFunc runs an external command; args[0] is the command name as typed
+type ExecFunc func(ctx context.Context, hc HandlerContext, args []string) error
+
+// ExecHandler wraps the way external commands are run. It sees every
+// external command before it is started and can run it by calling next,
+// refuse it by returning an error, or emulate it by writing to hc.Stdout.
+type ExecHandler func(next ExecFunc) ExecFunc
+
+// waitDelay bounds how long Wait keeps copying I/O after a command exited,
+// e.g. when stdin is a reader that never reaches EOF
+const waitDelay = time.Second
+
+// notFoundError is returned when neither a builtin nor a file in PATH
+// matches the command name
+type notFoundError struct {
+	name string
+}
+
+func (e *notFoundError) Error() string {
+	return e.name + " command not found"
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == builtins.ErrCmdNotFound
+}
+
+// runPipeline starts all commands at once, connecting the output of each to
+// the input of the next with a pipe. The error deciding the status of the
+// pipeline is returned, the others are reported. If u is not nil the
+// consumed resources are added to it.
+func (s *Shell) runPipeline(ctx context.Context, pipeline models.Pipeline, u *usage) error {
+	if len(pipeline) == 0 {
+		return nil
+	}
+
+	job := s.builtin.Jobs().NextJob()
+	if len(pipeline) == 1 && pipeline[0].Name == "exec" && s.builtin.IsBuiltin("exec") {
+		return s.runExec(ctx, pipeline[0], job, u)
+	}
+
+	errs := make([]error, len(pipeline))
+	var wg sync.WaitGroup
+
+	var prev *os.File // prev is the read end of the previous command's pipe
+	for i, cmd := range pipeline {
+		stdio := builtins.IO{Stdin: s.stdin, Stdout: s.stdout, Stderr: s.stderr, ExtraFiles: s.files}
+		if prev != nil {
+			stdio.Stdin = prev
+		}
+
+		var next, pw *os.File
+		if i < len(pipeline)-1 {
+			var err error
+			next, pw, err = os.Pipe()
+			if err != nil {
+				if prev != nil {
+					prev.Close()
+				}
+				wg.Wait()
+				return err
+			}
+			stdio.Stdout = pw
+		}
+
+		s.traceCommand(cmd)
+		wg.Add(1)
+		go func(i int, cmd models.Command, stdio builtins.IO, in, out *os.File) {
+			defer wg.Done()
+			errs[i] = s.runCommand(ctx, cmd, stdio, job, u, nil)
+			// closing our ends lets the neighbours see EOF or a broken pipe
+			if out != nil {
+				out.Close()
+			}
+			if in != nil {
+				in.Close()
+			}
+		}(i, cmd, stdio, prev, pw)
+
+		prev = next
+	}
+	wg.Wait()
+
+	// the status of a pipeline is the one of its last command or, with
+	// pipefail, of the last command that failed
+	last := len(errs) - 1
+	if s.builtin.Option("pipefail") {
+		for i := len(errs) - 1; i >= 0; i-- {
+			if errs[i] != nil {
+				last = i
+				break
+			}
+		}
+	}
+	for i, err := range errs {
+		if i != last && err != nil {
+			s.reportError(err)
+		}
+	}
+	return errs[last]
+}
+
+// runCommand runs a single command of a pipeline: one of the builtins the
+// shell runs itself (timeout, xargs, find), a builtin, or an external
+// program
+func (s *Shell) runCommand(ctx context.Context, cmd models.Command, stdio builtins.IO, job int, u *usage, to *timeoutOptions) (err error) {
+	rec := s.beginTrace(cmd)
+	defer func() { s.endTrace(rec, err) }()
+
+	if err := s.checkRestricted(cmd); err != nil {
+		return err
+	}
+
+	stdio, opened, err := s.redirect(cmd.Redirects, stdio)
+	defer closeFiles(opened)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !s.builtin.IsBuiltin(cmd.Name):
+	case cmd.Name == "timeout":
+		return s.runTimeout(ctx, cmd, stdio, job, u)
+	case cmd.Name == "xargs":
+		return s.runXargs(ctx, cmd, stdio, job, u)
+	case cmd.Name == "find":
+		return s.runFind(ctx, cmd, stdio, job, u)
+	case cmd.Name == "exec" && len(cmd.Args) == 0:
+		// in a pipeline exec only affects its own command
+		return nil
+	}
+
+	cmd, external := s.bypassBuiltin(cmd)
+	if !external {
+		var runErr error
+		u.measureBuiltin(func() {
+			runErr = s.builtin.Run(ctx, cmd.Name, stdio, cmd.Args...)
+		})
+		if !errors.Is(runErr, builtins.ErrCmdNotFound) {
+			return runErr
+		}
+	}
+
+	return s.runExternal(ctx, cmd, stdio, job, u, to, rec)
+}
+
+// runExec implements exec. Without a command its redirections stay in
+// effect for the following commands. With one, the command is run as if it
+// replaced the shell: the shell stops with its exit status.
+func (s *Shell) runExec(ctx context.Context, cmd models.Command, job int, u *usage) (err error) {
+	s.traceCommand(cmd)
+	if len(cmd.Args) > 0 {
+		stdio := builtins.IO{Stdin: s.stdin, Stdout: s.stdout, Stderr: s.stderr, ExtraFiles: s.files}
+		err := s.runCommand(ctx, cmd, stdio, job, u, nil)
+		return &exitError{status: builtins.ExitStatus(err), err: err}
+	}
+
+	rec := s.beginTrace(cmd)
+	defer func() { s.endTrace(rec, err) }()
+	if err := s.checkRestricted(cmd); err != nil {
+		return err
+	}
+	return s.execRedirects(cmd.Redirects)
+}
+
+// runExternal runs an external program through the exec handlers
+func (s *Shell) runExternal(ctx context.Context, cmd models.Command, stdio builtins.IO, job int, u *usage, to *timeoutOptions, rec *traceRecord) error {
+	run := s.execFunc(job, u, to, rec)
+	for i := len(s.execHandlers) - 1; i >= 0; i-- {
+		run = s.execHandlers[i](run)
+	}
+
+	hc := HandlerContext{
+		Dir:    s.builtin.Dir(),
+		Env:    s.env.Environ(),
+		Stdin:  stdio.Stdin,
+		Stdout: stdio.Stdout,
+		Stderr: stdio.Stderr,
+
+		ExtraFiles: stdio.ExtraFiles,
+	}
+	return run(ctx, hc, append([]string{cmd.Name}, cmd.Args...))
+}
+
+// execFunc returns the innermost ExecFunc, which starts the process and
+// waits for it. The process is killed when ctx is done; under timeout the
+// whole process group is signalled.
+func (s *Shell) execFunc(job int, u *usage, to *timeoutOptions, rec *traceRecord) ExecFunc {
+	return func(ctx context.Context, hc HandlerContext, args []string) error {
+		path, err := s.builtin.LookPath(args[0])
+		if err != nil {
+			return &notFoundError{name: args[0]}
+		}
+
+		execCmd := exec.CommandContext(ctx, path, args[1:]...)
+		execCmd.Args[0] = args[0]
+		execCmd.Dir = hc.Dir
+		execCmd.Env = hc.Env
+		execCmd.Stdin = hc.Stdin
+		execCmd.Stdout = hc.Stdout
+		execCmd.Stderr = hc.Stderr
+		execCmd.ExtraFiles = hc.ExtraFiles
+		execCmd.WaitDelay = waitDelay
+		stopTimeout := func() {}
+		if to != nil {
+			stopTimeout = to.apply(execCmd)
+		}
+
+		if err := execCmd.Start(); err != nil {
+			return err
+		}
+
+		rec.started(execCmd.Process.Pid)
+		s.builtin.Jobs().Add(models.Process{PID: execCmd.Process.Pid, Job: job, Cmd: args[0]})
+		err = execCmd.Wait()
+		stopTimeout()
+		u.addProcess(execCmd.ProcessState)
+		s.builtin.Jobs().Remove(execCmd.Process.Pid)
+
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// the command succeeded, only its stdin reader never hit EOF
+			err = nil
+		}
+		// under timeout the status of the command is kept, which runTimeout
+		// returns with --preserve-status
+		timedOut := to != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+		if err != nil && ctx.Err() != nil && !timedOut {
+			return ctx.Err()
+		}
+		return err
+	}
+}
+
+// bypassBuiltin turns "command name args..." and "exec name args..." into
+// a call of the external name, so that e.g. the system grep can be run
+// instead of the builtin one
+func (s *Shell) bypassBuiltin(cmd models.Command) (models.Command, bool) {
+	if (cmd.Name != "command" && cmd.Name != "exec") || !s.builtin.IsBuiltin(cmd.Name) {
+		return cmd, false
+	}
+	if cmd.Name == "exec" && len(cmd.Args) > 0 && cmd.Args[0] == "--" {
+		cmd.Args = cmd.Args[1:]
+	}
+	if len(cmd.Args) == 0 || (cmd.Name == "command" && strings.HasPrefix(cmd.Args[0], "-")) {
+		return cmd, false
+	}
+	cmd.Name = cmd.Args[0]
+	cmd.Args = cmd.Args[1:]
+	return cmd, true
+}
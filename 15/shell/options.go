@@ -0,0 +1,75 @@
+package shell
+
+import "io"
+
+// config collects the settings applied by options before the shell is built
+type config struct {
+	stdin        io.Reader
+	stdout       io.Writer
+	stderr       io.Writer
+	env          []string
+	dir          string
+	builtins     []string
+	execHandlers []ExecHandler
+	restricted   bool
+	allow        []string
+	traceJSON    io.Writer
+}
+
+// Option configures a Shell created with New
+type Option func(*config)
+
+// WithStdin sets the standard input of the shell and its commands
+func WithStdin(r io.Reader) Option {
+	return func(c *config) { c.stdin = r }
+}
+
+// WithStdout sets the standard output of the shell and its commands
+func WithStdout(w io.Writer) Option {
+	return func(c *config) { c.stdout = w }
+}
+
+// WithStderr sets the standard error of the shell and its commands
+func WithStderr(w io.Writer) Option {
+	return func(c *config) { c.stderr = w }
+}
+
+// WithEnv sets the initial variables as KEY=VALUE pairs instead of the
+// process environment
+func WithEnv(environ []string) Option {
+	return func(c *config) { c.env = environ }
+}
+
+// WithDir sets the initial working directory
+func WithDir(dir string) Option {
+	return func(c *config) { c.dir = dir }
+}
+
+// WithBuiltins enables only the named builtins; other names are looked up
+// in PATH like any external command
+func WithBuiltins(names ...string) Option {
+	return func(c *config) { c.builtins = append([]string{}, names...) }
+}
+
+// WithExecHandler adds a handler that wraps how external commands are run.
+// Handlers added first are called first.
+func WithExecHandler(h ExecHandler) Option {
+	return func(c *config) { c.execHandlers = append(c.execHandlers, h) }
+}
+
+// WithRestricted makes the shell refuse cd, redirections and files written
+// by builtins at absolute paths or out of its directory, changes of PATH
+// and every external command not named in allow
+func WithRestricted(allow ...string) Option {
+	return func(c *config) {
+		c.restricted = true
+		c.allow = append([]string{}, allow...)
+	}
+}
+
+// WithTraceJSON writes a JSON record to w for every command the shell runs,
+// with its arguments, directory, start and end time, exit status, PID and
+// redirections
+func WithTraceJSON(w io.Writer) Option {
+	return func(c *config) { c.traceJSON = w }
+}
@@ -0,0 +1,215 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"minishell/internal/builtins"
+	"minishell/internal/models"
+)
+
+// maxFD is the highest descriptor a redirection can name
+const maxFD = 9
+
+// closedWriter stands for an output descriptor closed with >&-
+type closedWriter struct{}
+
+func (closedWriter) Write([]byte) (int, error) {
+	return 0, syscall.EBADF
+}
+
+// redirect applies redirections from left to right to a copy of stdio,
+// opening files relative to the shell's directory. The opened files are
+// returned for the caller to close, also when an error occurs.
+func (s *Shell) redirect(redirects []models.Redirect, stdio builtins.IO) (builtins.IO, []*os.File, error) {
+	stdio.ExtraFiles = slices.Clone(stdio.ExtraFiles)
+	var opened []*os.File
+
+	for _, r := range redirects {
+		if r.FD > maxFD {
+			return stdio, opened, fmt.Errorf("%d: bad file descriptor", r.FD)
+		}
+
+		switch r.Op {
+		case "<":
+			f, err := os.Open(s.builtin.Abs(r.Target))
+			if err != nil {
+				return stdio, opened, fmt.Errorf("cannot read file %s: %w", r.Target, err)
+			}
+			opened = append(opened, f)
+			setFD(&stdio, r.FD, f)
+		case ">", ">>":
+			flags := os.O_CREATE | os.O_WRONLY
+			if r.Op == ">>" {
+				flags |= os.O_APPEND
+			} else {
+				flags |= os.O_TRUNC
+			}
+			f, err := os.OpenFile(s.builtin.Abs(r.Target), flags, 0644)
+			if err != nil {
+				return stdio, opened, fmt.Errorf("cannot write to file %s: %w", r.Target, err)
+			}
+			opened = append(opened, f)
+			setFD(&stdio, r.FD, f)
+		case "<&", ">&":
+			f, err := dupFD(&stdio, r)
+			if f != nil {
+				opened = append(opened, f)
+			}
+			if err != nil {
+				return stdio, opened, err
+			}
+		}
+	}
+	return stdio, opened, nil
+}
+
+// dupFD makes r.FD a copy of the descriptor r.Target, or closes it for
+// "-". Descriptors from 3 on are passed to external commands, so they get
+// their own file, which is returned.
+func dupFD(stdio *builtins.IO, r models.Redirect) (*os.File, error) {
+	if r.Target == "-" {
+		setFD(stdio, r.FD, nil)
+		return nil, nil
+	}
+
+	from, err := strconv.Atoi(r.Target)
+	if err != nil || from < 0 || from > maxFD {
+		return nil, fmt.Errorf("%s: bad file descriptor", r.Target)
+	}
+	src := getFD(stdio, from)
+	if src == nil {
+		return nil, fmt.Errorf("%d: bad file descriptor", from)
+	}
+	if r.FD < 3 {
+		if r.FD == 0 {
+			if _, ok := src.(io.Reader); !ok {
+				return nil, fmt.Errorf("%d: bad file descriptor", from)
+			}
+		} else if _, ok := src.(io.Writer); !ok {
+			return nil, fmt.Errorf("%d: bad file descriptor", from)
+		}
+		setFD(stdio, r.FD, src)
+		return nil, nil
+	}
+
+	f, ok := src.(*os.File)
+	if !ok {
+		return nil, fmt.Errorf("%d: not a file, cannot duplicate to %d", from, r.FD)
+	}
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("%d: %w", from, err)
+	}
+	dup := os.NewFile(uintptr(fd), f.Name())
+	setFD(stdio, r.FD, dup)
+	return dup, nil
+}
+
+// getFD returns the stream or file behind a descriptor, or nil if closed
+func getFD(stdio *builtins.IO, fd int) any {
+	switch fd {
+	case 0:
+		if stdio.Stdin == nil {
+			return nil
+		}
+		return stdio.Stdin
+	case 1:
+		if _, ok := stdio.Stdout.(closedWriter); ok || stdio.Stdout == nil {
+			return nil
+		}
+		return stdio.Stdout
+	case 2:
+		if _, ok := stdio.Stderr.(closedWriter); ok || stdio.Stderr == nil {
+			return nil
+		}
+		return stdio.Stderr
+	}
+	if fd-3 >= len(stdio.ExtraFiles) || stdio.ExtraFiles[fd-3] == nil {
+		return nil
+	}
+	return stdio.ExtraFiles[fd-3]
+}
+
+// setFD makes a descriptor refer to v; nil closes it
+func setFD(stdio *builtins.IO, fd int, v any) {
+	switch fd {
+	case 0:
+		r, _ := v.(io.Reader)
+		stdio.Stdin = r
+		return
+	case 1, 2:
+		w, ok := v.(io.Writer)
+		if !ok {
+			w = closedWriter{}
+		}
+		if fd == 1 {
+			stdio.Stdout = w
+		} else {
+			stdio.Stderr = w
+		}
+		return
+	}
+
+	for len(stdio.ExtraFiles) <= fd-3 {
+		stdio.ExtraFiles = append(stdio.ExtraFiles, nil)
+	}
+	f, _ := v.(*os.File)
+	stdio.ExtraFiles[fd-3] = f
+}
+
+// execRedirects makes the redirections of an exec without a command apply
+// to every following command, closing the files they replace
+func (s *Shell) execRedirects(redirects []models.Redirect) error {
+	stdio := builtins.IO{Stdin: s.stdin, Stdout: s.stdout, Stderr: s.stderr, ExtraFiles: s.files}
+	stdio, opened, err := s.redirect(redirects, stdio)
+	if err != nil {
+		closeFiles(opened)
+		return err
+	}
+
+	s.stdin = stdio.Stdin
+	s.stdout = lockWriter(stdio.Stdout)
+	s.stderr = lockWriter(stdio.Stderr)
+	s.files = stdio.ExtraFiles
+
+	owned := append(s.owned, opened...)
+	s.owned = nil
+	for _, f := range owned {
+		if s.usesFile(f) {
+			s.owned = append(s.owned, f)
+		} else {
+			f.Close()
+		}
+	}
+	return nil
+}
+
+// usesFile reports whether one of the shell's descriptors refers to f
+func (s *Shell) usesFile(f *os.File) bool {
+	return s.stdin == io.Reader(f) || s.stdout == io.Writer(f) || s.stderr == io.Writer(f) || slices.Contains(s.files, f)
+}
+
+// Close closes the files the shell keeps open because of exec redirections
+func (s *Shell) Close() error {
+	var errs []error
+	for _, f := range s.owned {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.owned, s.files = nil, nil
+	return errors.Join(errs...)
+}
+
+// closeFiles closes files opened for a command
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
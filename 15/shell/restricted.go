@@ -0,0 +1,50 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"minishell/internal/builtins"
+	"minishell/internal/models"
+)
+
+// ErrRestricted is returned for what a restricted shell refuses to do
+var ErrRestricted = builtins.ErrRestricted
+
+// AllowList returns an ExecHandler that only lets the named external
+// commands through. Names are compared with the command as typed, so
+// "/bin/ls" is refused even if "ls" is allowed.
+func AllowList(names ...string) ExecHandler {
+	allowed := slices.Clone(names)
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, hc HandlerContext, args []string) error {
+			if !slices.Contains(allowed, args[0]) {
+				return fmt.Errorf("%s: %w", args[0], ErrRestricted)
+			}
+			return next(ctx, hc, args)
+		}
+	}
+}
+
+// checkRestricted refuses cd and redirections leaving the shell's directory
+// when the shell is restricted; the builtins check the files they write
+// themselves
+func (s *Shell) checkRestricted(cmd models.Command) error {
+	if !s.restricted {
+		return nil
+	}
+	if cmd.Name == "cd" {
+		return fmt.Errorf("cd: %w", ErrRestricted)
+	}
+	for _, r := range cmd.Redirects {
+		if strings.HasSuffix(r.Op, "&") {
+			continue
+		}
+		if err := s.builtin.CheckWrite(r.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+// Package shell implements the minishell interpreter. It can run
+// interactively on a terminal with Run, or be embedded and driven with Exec.
+package shell
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"minishell/internal/builtins"
+	"minishell/internal/env"
+	"minishell/internal/models"
+	"minishell/internal/parser"
+)
+
+// Colors
+var (
+	red     = "\u001b[31m"
+	blue    = "\u001b[34m"
+	green   = "\u001b[32m"
+	yellow  = "\u001b[33m"
+	magenta = "\u001b[35m"
+	reset   = "\u001b[0m"
+)
+
+// Shell represents a shell with builtins commands
+type Shell struct {
+	builtin *builtins.Builtins
+	env     *env.Env
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	files []*os.File // files are the descriptors from 3 on opened by exec
+	owned []*os.File // owned are the files opened by exec, closed by Close
+
+	execHandlers []ExecHandler
+	restricted   bool
+	trace        *traceLog // trace is nil unless a JSON trace is written
+
+	status int // status is the exit status of the last job, as in $?
+
+	traps trapState
+
+	mu         sync.Mutex
+	cancelLine context.CancelFunc // cancelLine stops the command line being run
+}
+
+// exitError makes the shell stop with a status, e.g. because of set -e
+type exitError struct {
+	status int
+	err    error // err is the error of the command that failed, if any
+}
+
+func (e *exitError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("exit %d", e.status)
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// New returns a shell configured by opts. By default it uses the standard
+// streams, the environment and the working directory of the process.
+func New(opts ...Option) (*Shell, error) {
+	cfg := config{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		env:    os.Environ(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		cfg.dir = wd
+	}
+	info, err := os.Stat(cfg.dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory", cfg.dir)
+	}
+
+	s := &Shell{
+		env:          env.New(cfg.env),
+		stdin:        cfg.stdin,
+		stdout:       lockWriter(cfg.stdout),
+		stderr:       lockWriter(cfg.stderr),
+		execHandlers: slices.Clone(cfg.execHandlers),
+		restricted:   cfg.restricted,
+	}
+	if cfg.traceJSON != nil {
+		s.trace = newTraceLog(cfg.traceJSON)
+	}
+	s.builtin = builtins.New(s.env, cfg.dir)
+	if cfg.builtins != nil {
+		if err := s.builtin.Only(cfg.builtins...); err != nil {
+			return nil, err
+		}
+	}
+	s.env.Set("PWD", s.builtin.Dir())
+
+	if s.restricted {
+		s.builtin.Restrict()
+		// the allow-list goes last, so that handlers added by the caller
+		// can still emulate commands it does not name
+		s.execHandlers = append(s.execHandlers, AllowList(cfg.allow...))
+		s.env.SetReadonly("PATH")
+	}
+
+	return s, nil
+}
+
+// Run runs the shell interactively until exit or end of input
+func (s *Shell) Run() error {
+	// Handle Ctrl+C
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT)
+	defer signal.Stop(sigChan)
+	go func() {
+		for range sigChan {
+			s.mu.Lock()
+			cancel := s.cancelLine
+			s.mu.Unlock()
+			if cancel != nil {
+				cancel()
+				fmt.Fprintln(s.stdout)
+				s.printConsoleLine()
+			}
+		}
+	}()
+	defer s.stopTraps()
+
+	reader := bufio.NewReader(s.stdin)
+
+	for {
+		fmt.Fprintln(s.stdout)
+		s.printConsoleLine()
+
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF { // Ctrl+D
+				break
+			}
+			s.readingLineError(err)
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, ok := parseExit(line); ok {
+			break
+		}
+
+		var exit *exitError
+		if errors.As(s.runInterruptible(line), &exit) {
+			break
+		}
+		if _, exited := s.afterLine(context.Background()); exited {
+			break
+		}
+	}
+
+	s.runTrap(context.Background(), "EXIT")
+	return nil
+}
+
+// Exec runs a script, one command line per line, and returns the exit
+// status of the last command together with its error. Errors of the
+// commands are also reported on the shell's stderr, as a terminal user
+// would see them. The EXIT trap, if any, runs when the script ends.
+func (s *Shell) Exec(ctx context.Context, script string) (exitCode int, err error) {
+	defer s.stopTraps()
+
+	exitCode, _, err = s.execLines(ctx, script)
+	if code, exited := s.runTrap(ctx, "EXIT"); exited {
+		exitCode = code
+	}
+	return exitCode, err
+}
+
+// execLines runs the lines of a script and reports whether it ended with
+// exit, set -e or an interruption rather than by running out of lines
+func (s *Shell) execLines(ctx context.Context, script string) (exitCode int, exited bool, err error) {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if code, ok := parseExit(line); ok {
+			if code < 0 {
+				code = s.status
+			}
+			return code, true, nil
+		}
+
+		err = s.runLine(ctx, line)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			return exit.status, true, exit.err
+		}
+		if ctx.Err() != nil {
+			return builtins.ExitStatus(ctx.Err()), true, ctx.Err()
+		}
+		if code, exited := s.afterLine(ctx); exited {
+			return code, true, err
+		}
+	}
+	return builtins.ExitStatus(err), false, err
+}
+
+// runInterruptible runs a line so that Ctrl+C stops it instead of the shell
+func (s *Shell) runInterruptible(line string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.mu.Lock()
+	s.cancelLine = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.cancelLine = nil
+		s.mu.Unlock()
+	}()
+
+	return s.runLine(ctx, line)
+}
+
+// runLine runs the jobs of a command line, honouring && and ||, and returns
+// the error of the last one that ran. It returns an *exitError when the
+// shell has to stop because of set -e or set -u.
+func (s *Shell) runLine(ctx context.Context, line string) error {
+	if s.builtin.Option("verbose") {
+		fmt.Fprintln(s.stderr, line)
+	}
+
+	p := parser.Parser{
+		Lookup:  s.lookup,
+		Dir:     s.builtin.Dir(),
+		Nounset: s.builtin.Option("nounset"),
+	}
+	jobs, err := p.Parse(line)
+	if err != nil {
+		if errors.Is(err, parser.ErrSyntax) {
+			err = &builtins.StatusError{Status: 2, Err: err}
+		}
+		s.reportError(err)
+		s.status = builtins.ExitStatus(err)
+		if errors.Is(err, parser.ErrUnbound) {
+			return &exitError{status: s.status, err: err}
+		}
+		return err
+	}
+
+	last := -1 // last is the index of the last job that ran
+	for i, job := range jobs {
+		if (job.CondAfter == models.And && err != nil) || (job.CondAfter == models.Or && err == nil) {
+			continue
+		}
+		last = i
+
+		var u *usage
+		if job.Timed {
+			u = newUsage()
+		}
+		err = s.runJob(ctx, job, u)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			if exit.err != nil {
+				s.reportError(exit.err)
+			}
+			s.status = exit.status
+			return err
+		}
+		s.status = builtins.ExitStatus(err)
+		if err != nil {
+			s.reportError(err)
+		}
+		if u != nil {
+			s.reportTime(u, job.TimePosix)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	// like errexit, the ERR trap ignores all but the last command of a
+	// && or || list
+	if err != nil && last == len(jobs)-1 {
+		if code, exited := s.runTrap(ctx, "ERR"); exited {
+			return &exitError{status: code}
+		}
+		if s.builtin.Option("errexit") {
+			return &exitError{status: s.status, err: err}
+		}
+	}
+	return err
+}
+func (s *Shell) runJob(ctx context.Context, job models.Job, u *usage) error {
+	var err error
+	for _, pipeline := range job.Pipelines {
+		err = s.runPipeline(ctx, pipeline, u)
+		if err != nil {
+			break
+		}
+	}
+	return err
+}
+
+// lookup returns the value of a variable for the parser, including the
+// special parameters $? and $$
+func (s *Shell) lookup(name string) (string, bool) {
+	switch name {
+	case "?":
+		return strconv.Itoa(s.status), true
+	case "$":
+		return strconv.Itoa(os.Getpid()), true
+	}
+	return s.env.Lookup(name)
+}
+
+// parseExit recognizes "exit [N]"; the code is -1 when N is omitted
+func parseExit(line string) (int, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "exit" {
+		return 0, false
+	}
+	if len(fields) == 1 {
+		return -1, true
+	}
+	code, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 2, true
+	}
+	return code & 0xff, true
+}
+
+func (s *Shell) printConsoleLine() {
+	fmt.Fprint(s.stdout, magenta+"minishell"+reset+" "+blue+s.builtin.Dir()+reset+" $ ")
+}
+
+// reportError prints the error of a job the way the user expects to see it
+func (s *Shell) reportError(err error) {
+	var notFound *notFoundError
+	switch {
+	case errors.As(err, &notFound):
+		s.commandNotFound(notFound.name)
+	case errors.Is(err, context.Canceled), errors.Is(err, syscall.EPIPE):
+	case builtins.ExitStatus(err) == 128+int(syscall.SIGPIPE):
+		// the reader of a pipe went away, that is how pipelines end early
+	default:
+		s.handleError(err)
+	}
+}
+
+// lockedWriter serializes writes to a writer that is not a file, since the
+// commands of a pipeline write to the shell's streams concurrently
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// lockWriter wraps w in a lockedWriter unless it is a file, which external
+// commands can then use directly
+func lockWriter(w io.Writer) io.Writer {
+	switch w.(type) {
+	case *os.File, *lockedWriter, closedWriter:
+		return w
+	}
+	return &lockedWriter{w: w}
+}
+
+func (s *Shell) commandNotFound(cmd string) {
+	fmt.Fprintf(s.stderr, "%s command not found\n", cmd)
+}
+
+func (s *Shell) readingLineError(err error) {
+	fmt.Fprintln(s.stderr, "error reading line:", err)
+}
+
+func (s *Shell) handleError(err error) {
+	fmt.Fprintln(s.stderr, "error:", err)
+}
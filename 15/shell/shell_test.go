@@ -0,0 +1,1067 @@
+package shell_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"minishell/shell"
+)
+
+// newShell returns a shell running in a temporary directory with in-memory
+// streams
+func newShell(t *testing.T, opts ...shell.Option) (*shell.Shell, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	opts = append([]shell.Option{
+		shell.WithStdin(strings.NewReader("")),
+		shell.WithStdout(&stdout),
+		shell.WithStderr(&stderr),
+		shell.WithEnv([]string{"PATH=" + os.Getenv("PATH"), "HOME=" + t.TempDir()}),
+		shell.WithDir(t.TempDir()),
+	}, opts...)
+
+	sh, err := shell.New(opts...)
+	require.NoError(t, err)
+	return sh, &stdout, &stderr
+}
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantErr    string
+		wantStatus int
+	}{
+		{
+			name:    "builtin echo",
+			script:  "echo hello world",
+			wantOut: "hello world\n",
+		},
+		{
+			name:    "builtin pipeline",
+			script:  "echo banana | sort",
+			wantOut: "banana\n",
+		},
+		{
+			name:    "external command in pipeline",
+			script:  "echo hello | cat",
+			wantOut: "hello\n",
+		},
+		{
+			name:    "external to builtin",
+			script:  "printf 'b\\na\\n' | sort",
+			wantOut: "a\nb\n",
+		},
+		{
+			name:    "several lines",
+			script:  "echo one\n# comment\n\necho two",
+			wantOut: "one\ntwo\n",
+		},
+		{
+			name:       "failing external command",
+			script:     "false",
+			wantStatus: 1,
+			wantErr:    "error: exit status 1\n",
+		},
+		{
+			name:       "unknown command",
+			script:     "nosuchcommand",
+			wantStatus: 127,
+			wantErr:    "nosuchcommand command not found\n",
+		},
+		{
+			name:       "exit with status",
+			script:     "exit 3\necho not reached",
+			wantStatus: 3,
+		},
+		{
+			name:       "timeout",
+			script:     "timeout 0.1 sleep 5",
+			wantStatus: 124,
+			wantErr:    "error: exit status 124\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, stderr := newShell(t)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+			assert.Equal(t, tt.wantErr, stderr.String())
+		})
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantStatus int
+	}{
+		{name: "in time", script: "timeout 5 sh -c 'exit 3'", wantStatus: 3},
+		{name: "timed out", script: "timeout 0.1 sleep 5", wantStatus: 124},
+		{name: "kill signal", script: "timeout -s KILL 0.1 sleep 5", wantStatus: 128 + 9},
+		{name: "kill after", script: "timeout -k 0.2 0.1 sh -c 'trap \"\" TERM; sleep 5'", wantStatus: 124},
+		{name: "preserve status", script: "timeout --preserve-status 0.1 sleep 5", wantStatus: 128 + 15},
+		{name: "preserve status of signal", script: "timeout --preserve-status -s INT 0.1 sleep 5", wantStatus: 128 + 2},
+		{name: "preserve exit status", script: "timeout --preserve-status 0.1 sh -c 'trap \"exit 3\" TERM; sleep 5 & wait'", wantStatus: 3},
+		{name: "preserve after kill", script: "timeout --preserve-status -k 0.2 0.1 sh -c 'trap \"\" TERM; sleep 5'", wantStatus: 128 + 9},
+		{name: "invalid duration", script: "timeout soon sleep 1", wantStatus: 125},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, _, _ := newShell(t)
+
+			start := time.Now()
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Less(t, time.Since(start), 3*time.Second, "the command was stopped")
+		})
+	}
+}
+
+func TestExecTime(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		timeFormat []string // timeFormat sets TIMEFORMAT when not nil
+		wantOut    string
+		wantErr    string // wantErr is a regular expression
+		wantStatus int
+	}{
+		{
+			name:    "default format",
+			script:  "time echo hi",
+			wantOut: "hi\n",
+			wantErr: `^\nreal\t0m0\.\d{3}s\nuser\t0m0\.\d{3}s\nsys\t0m0\.\d{3}s\nmaxrss\t\d+K\n$`,
+		},
+		{
+			name:    "posix format",
+			script:  "time -p echo hi | cat",
+			wantOut: "hi\n",
+			wantErr: `^real 0\.\d{2}\nuser 0\.\d{2}\nsys 0\.\d{2}\n$`,
+		},
+		{
+			name:       "posix format ignores TIMEFORMAT",
+			script:     "time -p echo hi",
+			timeFormat: []string{"TIMEFORMAT=%R"},
+			wantOut:    "hi\n",
+			wantErr:    `^real 0\.\d{2}\n`,
+		},
+		{
+			name:       "custom format",
+			script:     "time echo hi",
+			timeFormat: []string{"TIMEFORMAT=took %1R s, %P%% cpu"},
+			wantOut:    "hi\n",
+			wantErr:    `^took 0\.\d s, \d+\.\d{2}% cpu\n$`,
+		},
+		{
+			name:       "empty format",
+			script:     "time echo hi",
+			timeFormat: []string{"TIMEFORMAT="},
+			wantOut:    "hi\n",
+			wantErr:    `^$`,
+		},
+		{
+			name:       "status is kept",
+			script:     "time false",
+			timeFormat: []string{"TIMEFORMAT=%0R"},
+			wantErr:    `^error: exit status 1\n0\n$`,
+			wantStatus: 1,
+		},
+		{
+			name:       "only the timed job",
+			script:     "time echo a && echo b",
+			timeFormat: []string{"TIMEFORMAT=%0R"},
+			wantOut:    "a\nb\n",
+			wantErr:    `^0\n$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []shell.Option
+			if tt.timeFormat != nil {
+				opts = append(opts, shell.WithEnv(append(tt.timeFormat, "PATH="+os.Getenv("PATH"))))
+			}
+			sh, stdout, stderr := newShell(t, opts...)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+			assert.Regexp(t, tt.wantErr, stderr.String())
+		})
+	}
+}
+
+func TestExecTimeReal(t *testing.T) {
+	sh, _, stderr := newShell(t)
+
+	_, err := sh.Exec(context.Background(), "time -p sleep 0.2")
+	require.NoError(t, err)
+	assert.Regexp(t, `^real 0\.[2-9]\d\n`, stderr.String())
+}
+
+func TestExecEnv(t *testing.T) {
+	sh, stdout, _ := newShell(t, shell.WithEnv([]string{"NAME=world"}))
+
+	status, err := sh.Exec(context.Background(), "echo hello $NAME$NOPE/x")
+	require.NoError(t, err)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "hello world/x\n", stdout.String())
+}
+
+func TestExecDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file.txt"), nil, 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "sub"), filepath.Join(dir, "link")))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	sh, stdout, _ := newShell(t, shell.WithDir(dir))
+	_, err = sh.Exec(context.Background(), "pwd\ncd link\npwd\npwd -P\nls\ncd ..\npwd")
+	require.NoError(t, err)
+
+	want := []string{dir, filepath.Join(dir, "link"), filepath.Join(dir, "sub"), "file.txt", dir}
+	assert.Equal(t, strings.Join(want, "\n")+"\n", stdout.String())
+
+	// the directory of the Go process is left alone
+	after, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, wd, after)
+}
+
+func TestExecRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+
+	script := strings.Join([]string{
+		"cd sub",
+		"echo b > one.txt",
+		"echo a > two.txt",
+		"echo c >> two.txt",
+		"sort *.txt",
+		"grep -c c two.txt",
+		"cut -d . -f 1 < one.txt",
+		"cat two.txt | sort -r - one.txt",
+		"sort -r -o two.txt two.txt one.txt",
+		"cat two.txt",
+	}, "\n")
+
+	sh, stdout, stderr := newShell(t, shell.WithDir(dir))
+	_, err := sh.Exec(context.Background(), script)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a\nb\nc\n1\nb\nc\nb\na\nc\nb\na\n", stdout.String())
+	assert.Empty(t, stderr.String())
+	assert.FileExists(t, filepath.Join(dir, "sub", "one.txt"))
+
+	_, err = sh.Exec(context.Background(), "grep x missing.txt")
+	assert.Error(t, err)
+}
+
+func TestExecBuiltins(t *testing.T) {
+	sh, stdout, _ := newShell(t, shell.WithBuiltins("echo", "type"))
+
+	_, err := sh.Exec(context.Background(), "type echo grep")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "echo is a shell builtin", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "grep is /"), lines[1])
+
+	_, err = shell.New(shell.WithBuiltins("nosuch"))
+	assert.Error(t, err)
+}
+
+func TestExecHash(t *testing.T) {
+	bin := t.TempDir()
+	tool := filepath.Join(bin, "tool")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho tool\n"), 0o755))
+
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{name: "empty", script: "hash", wantOut: "hash: hash table empty\n"},
+		{name: "hits", script: "hash tool\nhash\ntool\ntool\nhash", wantOut: "hits   command\n   0   %[1]s\ntool\ntool\nhits   command\n   2   %[1]s\n"},
+		{name: "reset", script: "tool\nhash -r\nhash", wantOut: "tool\nhash: hash table empty\n"},
+		{name: "reset and add", script: "tool\nhash -r tool\nhash", wantOut: "tool\nhits   command\n   0   %[1]s\n"},
+		{name: "forget", script: "hash tool\nhash -d tool\nhash", wantOut: "hash: hash table empty\n"},
+		{name: "forget not hashed", script: "hash -d tool", wantStatus: 1},
+		{name: "path", script: "hash -t tool\nhash tool\nhash -t tool", wantOut: "%[1]s\n"},
+		{name: "paths", script: "hash tool\nhash -t tool tool", wantOut: "tool\t%[1]s\ntool\t%[1]s\n"},
+		{name: "path name", script: "hash /bin/sh\nhash", wantOut: "hash: hash table empty\n"},
+		{name: "builtin", script: "hash echo\nhash", wantOut: "hash: hash table empty\n"},
+		{name: "not found", script: "hash nosuch", wantStatus: 1},
+		{name: "type", script: "type echo tool\nhash tool\ntype tool", wantOut: "echo is a shell builtin\ntool is %[1]s\ntool is hashed (%[1]s)\n"},
+		{name: "type not found", script: "type nosuch", wantStatus: 1},
+		{name: "which", script: "which tool", wantOut: "%[1]s\n"},
+		{name: "which not found", script: "which tool nosuch", wantOut: "%[1]s\n", wantStatus: 1},
+		{name: "command -v", script: "command -v echo tool", wantOut: "echo\n%[1]s\n"},
+		{name: "command -V", script: "command -V echo", wantOut: "echo is a shell builtin\n"},
+		{name: "command -v not found", script: "command -v nosuch", wantStatus: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t, shell.WithEnv([]string{"PATH=" + bin}))
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			want := tt.wantOut
+			if strings.Contains(want, "%[1]s") {
+				want = fmt.Sprintf(want, tool)
+			}
+			assert.Equal(t, want, stdout.String())
+		})
+	}
+}
+
+func TestExecHandler(t *testing.T) {
+	errDenied := errors.New("denied")
+	var mu sync.Mutex
+	var seen [][]string
+
+	handler := func(next shell.ExecFunc) shell.ExecFunc {
+		return func(ctx context.Context, hc shell.HandlerContext, args []string) error {
+			mu.Lock()
+			seen = append(seen, args)
+			mu.Unlock()
+			switch args[0] {
+			case "fake":
+				_, err := fmt.Fprintln(hc.Stdout, "faked", strings.Join(args[1:], " "))
+				return err
+			case "rm":
+				return errDenied
+			}
+			return next(ctx, hc, args)
+		}
+	}
+
+	sh, stdout, stderr := newShell(t, shell.WithExecHandler(handler))
+
+	status, err := sh.Exec(context.Background(), "fake a b | sed -n p\nrm -rf x")
+	assert.ErrorIs(t, err, errDenied)
+	assert.Equal(t, 1, status)
+	assert.Equal(t, "faked a b\n", stdout.String())
+	assert.Equal(t, "error: denied\n", stderr.String())
+	// the commands of a pipeline start concurrently
+	assert.ElementsMatch(t, [][]string{{"fake", "a", "b"}, {"sed", "-n", "p"}, {"rm", "-rf", "x"}}, seen)
+}
+
+func TestExecRedirect(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.txt")
+
+	sh, stdout, _ := newShell(t, shell.WithDir(dir))
+	script := fmt.Sprintf("echo b > %[1]s\necho a >> %[1]s\nsort < %[1]s", out)
+	_, err := sh.Exec(context.Background(), script)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a\nb\n", stdout.String())
+}
+
+func TestExecRestricted(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "allowed command",
+			script:  "echo hi | cat",
+			wantOut: "hi\n",
+		},
+		{
+			name:       "command not allowed",
+			script:     "ls",
+			wantStatus: 1,
+		},
+		{
+			name:       "path to an allowed command",
+			script:     "/bin/cat",
+			wantStatus: 1,
+		},
+		{
+			name:       "cd",
+			script:     "cd " + dir,
+			wantStatus: 1,
+		},
+		{
+			name:       "absolute redirection",
+			script:     "echo hi > " + filepath.Join(dir, "out.txt"),
+			wantStatus: 1,
+		},
+		{
+			name:       "redirection out of the directory",
+			script:     "echo hi > ../out.txt",
+			wantStatus: 1,
+		},
+		{
+			name:    "relative redirection",
+			script:  "echo hi > out.txt\ncat < out.txt",
+			wantOut: "hi\n",
+		},
+		{
+			name:       "command through timeout",
+			script:     "timeout 1 ls",
+			wantStatus: 1,
+		},
+		{
+			name:       "tee to an absolute path",
+			script:     "echo hi | tee " + filepath.Join(dir, "out.txt"),
+			wantOut:    "hi\n",
+			wantStatus: 1,
+		},
+		{
+			name:       "tee out of the directory",
+			script:     "echo hi | tee -a ../out.txt",
+			wantOut:    "hi\n",
+			wantStatus: 1,
+		},
+		{
+			name:    "tee in the directory",
+			script:  "echo hi | tee out.txt > copy.txt\ncat out.txt",
+			wantOut: "hi\n",
+		},
+		{
+			name:       "uniq output",
+			script:     "echo hi | uniq - " + filepath.Join(dir, "out.txt"),
+			wantStatus: 1,
+		},
+		{
+			name:       "sort output",
+			script:     "echo hi | sort -o " + filepath.Join(dir, "out.txt"),
+			wantStatus: 2,
+		},
+		{
+			name:       "sort temporary directory",
+			script:     "echo hi | sort -T " + dir,
+			wantStatus: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, stderr := newShell(t, shell.WithRestricted("cat"))
+
+			status, err := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+			if tt.wantStatus != 0 {
+				assert.ErrorIs(t, err, shell.ErrRestricted)
+				assert.Contains(t, stderr.String(), "restricted")
+			}
+		})
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "out.txt"))
+}
+
+func TestExecXtrace(t *testing.T) {
+	sh, stdout, stderr := newShell(t)
+
+	script := "set -x\necho a  b | cat\nset +x -v\necho c\nset +v\necho d"
+	_, err := sh.Exec(context.Background(), script)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a b\nc\nd\n", stdout.String())
+	assert.Equal(t, "+ echo a b\n+ cat\n+ set +x -v\necho c\nset +v\n", stderr.String())
+}
+
+func TestExecXtracePS4(t *testing.T) {
+	sh, _, stderr := newShell(t, shell.WithEnv([]string{"PS4=>> "}))
+
+	_, err := sh.Exec(context.Background(), "set -o xtrace\necho $NOPE a*b")
+	require.NoError(t, err)
+	assert.Equal(t, ">> echo '' 'a*b'\n", stderr.String())
+}
+
+func TestExecTraceJSON(t *testing.T) {
+	dir := t.TempDir()
+	var trace bytes.Buffer
+
+	sh, _, _ := newShell(t, shell.WithDir(dir), shell.WithTraceJSON(&trace))
+	status, _ := sh.Exec(context.Background(), "echo hi > out.txt\nsed -n p < out.txt >> copy.txt\nfalse")
+	assert.Equal(t, 1, status)
+
+	type redirect struct {
+		FD         int
+		Op, Target string
+	}
+	type record struct {
+		Argv         []string
+		Cwd          string
+		Start, End   time.Time
+		Exit         int
+		PID          int
+		Redirections []redirect
+	}
+	var records []record
+	dec := json.NewDecoder(&trace)
+	for dec.More() {
+		var rec record
+		require.NoError(t, dec.Decode(&rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 3)
+
+	assert.Equal(t, []string{"echo", "hi"}, records[0].Argv)
+	assert.Zero(t, records[0].PID)
+	assert.Equal(t, []redirect{{1, ">", "out.txt"}}, records[0].Redirections)
+
+	assert.Equal(t, []string{"sed", "-n", "p"}, records[1].Argv)
+	assert.NotZero(t, records[1].PID)
+	assert.Equal(t, []redirect{{0, "<", "out.txt"}, {1, ">>", "copy.txt"}}, records[1].Redirections)
+
+	assert.Equal(t, 1, records[2].Exit)
+	for _, rec := range records {
+		assert.Equal(t, dir, rec.Cwd)
+		assert.False(t, rec.End.Before(rec.Start))
+	}
+}
+
+func TestExecOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "and or lists",
+			script:  "false && echo no || echo yes\ntrue || echo no && echo yes",
+			wantOut: "yes\nyes\n",
+		},
+		{
+			name:    "status parameter",
+			script:  "false\necho $?\necho $?",
+			wantOut: "1\n0\n",
+		},
+		{
+			name:       "errexit",
+			script:     "set -e\necho a\nfalse\necho b",
+			wantOut:    "a\n",
+			wantStatus: 1,
+		},
+		{
+			name:    "errexit ignores conditions",
+			script:  "set -e\nfalse && echo no\nfalse || echo yes\necho end",
+			wantOut: "yes\nend\n",
+		},
+		{
+			name:       "errexit on the last command of a list",
+			script:     "set -e\ntrue && false\necho no",
+			wantStatus: 1,
+		},
+		{
+			name:       "nounset",
+			script:     "set -u\necho ${NOPE:-default}\necho $NOPE\necho no",
+			wantOut:    "default\n",
+			wantStatus: 1,
+		},
+		{
+			name:    "without pipefail",
+			script:  "false | true\necho $?",
+			wantOut: "0\n",
+		},
+		{
+			name:    "pipefail",
+			script:  "set -o pipefail\nsh -c 'exit 3' | false | true\necho $?\nsh -c 'exit 3' | true\necho $?",
+			wantOut: "1\n3\n",
+		},
+		{
+			name:    "quoted arguments",
+			script:  `echo "a  b" 'c | d' e\ f`,
+			wantOut: "a  b c | d e f\n",
+		},
+		{
+			name:       "syntax error",
+			script:     "echo 'a",
+			wantStatus: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+		})
+	}
+}
+
+func TestExecTrap(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "exit trap",
+			script:  "trap 'echo bye $?' EXIT\necho hi",
+			wantOut: "hi\nbye 0\n",
+		},
+		{
+			name:       "exit trap after exit",
+			script:     "trap 'echo bye' 0\nexit 4\necho no",
+			wantOut:    "bye\n",
+			wantStatus: 4,
+		},
+		{
+			name:       "err trap and errexit",
+			script:     "set -e\ntrap 'echo failed $?' ERR\ntrap 'echo bye' EXIT\nfalse || false\necho no",
+			wantOut:    "failed 1\nbye\n",
+			wantStatus: 1,
+		},
+		{
+			name:    "err trap ignores conditions",
+			script:  "trap 'echo failed' ERR\nfalse && true\nfalse | true",
+			wantOut: "",
+		},
+		{
+			name:       "exit from a trap",
+			script:     "trap 'exit 7' ERR\nfalse\necho no",
+			wantStatus: 7,
+		},
+		{
+			name:    "reset",
+			script:  "trap 'echo bye' EXIT\ntrap - EXIT\necho hi",
+			wantOut: "hi\n",
+		},
+		{
+			name:    "print",
+			script:  "trap 'echo it'\\''s' INT ERR\ntrap",
+			wantOut: "trap -- 'echo it'\\''s' ERR\ntrap -- 'echo it'\\''s' INT\n",
+		},
+		{
+			name:       "untrappable",
+			script:     "trap 'echo' KILL",
+			wantStatus: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+		})
+	}
+}
+
+func TestExecTrapSignal(t *testing.T) {
+	sh, stdout, _ := newShell(t)
+
+	// the action runs between lines once the signal has arrived
+	script := "trap 'echo got USR1' USR1\nkill -USR1 $$\nsleep 0.2\necho after\ntrap - USR1"
+	status, err := sh.Exec(context.Background(), script)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "got USR1\nafter\n", stdout.String())
+}
+
+func TestExecRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		stdin      string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "fields",
+			stdin:   "  one two  three four \n",
+			script:  "read a b c\necho \"[$a] [$b] [$c]\"",
+			wantOut: "[one] [two] [three four]\n",
+		},
+		{
+			name:    "reply",
+			stdin:   "  as is \n",
+			script:  "read\necho \"[$REPLY]\"",
+			wantOut: "[  as is ]\n",
+		},
+		{
+			name:    "line by line",
+			stdin:   "first\nsecond\n",
+			script:  "read x\nread y\necho $y $x",
+			wantOut: "second first\n",
+		},
+		{
+			name:    "ifs",
+			stdin:   "a:b::c\n",
+			script:  "IFS=: read a b c d\necho \"[$a] [$b] [$c] [$d]\"",
+			wantOut: "[a] [b] [] [c]\n",
+		},
+		{
+			name:    "backslashes",
+			stdin:   "a\\ b c\\\nd\n",
+			script:  "read x y\necho \"[$x] [$y]\"",
+			wantOut: "[a b] [cd]\n",
+		},
+		{
+			name:    "raw",
+			stdin:   "a\\ b\n",
+			script:  "read -r x y\necho \"[$x] [$y]\"",
+			wantOut: "[a\\] [b]\n",
+		},
+		{
+			name:    "delimiter",
+			stdin:   "a b;c",
+			script:  "read -d ';' x\necho $x",
+			wantOut: "a b\n",
+		},
+		{
+			name:    "end of input",
+			stdin:   "partial",
+			script:  "read x\necho $? $x",
+			wantOut: "1 partial\n",
+		},
+		{
+			name:    "from a pipe",
+			script:  "echo piped | read x\necho $x",
+			wantOut: "piped\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t, shell.WithStdin(strings.NewReader(tt.stdin)))
+
+			// IFS=: is no assignment syntax of the shell, so set it up front
+			script := strings.Replace(tt.script, "IFS=: ", "", 1)
+			if script != tt.script {
+				sh, stdout, _ = newShell(t,
+					shell.WithStdin(strings.NewReader(tt.stdin)),
+					shell.WithEnv([]string{"IFS=:"}))
+			}
+
+			status, _ := sh.Exec(context.Background(), script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+		})
+	}
+}
+
+func TestExecReadTimeout(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	sh, _, stderr := newShell(t, shell.WithStdin(r))
+	start := time.Now()
+	status, _ := sh.Exec(context.Background(), "read -t 0.1 -p 'name? ' x")
+	assert.Equal(t, 142, status)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.True(t, strings.HasPrefix(stderr.String(), "name? "), stderr.String())
+}
+
+func TestExecReadTimeoutKeepsInput(t *testing.T) {
+	// not a file, so the timed out read leaves a byte pending
+	r, w := io.Pipe()
+	defer w.Close()
+
+	sh, stdout, _ := newShell(t, shell.WithStdin(r))
+	status, _ := sh.Exec(context.Background(), "read -t 0.1 x")
+	assert.Equal(t, 142, status)
+
+	go io.WriteString(w, "xy\nz\n")
+	status, _ = sh.Exec(context.Background(), "read a\nread b\necho $a $b")
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "xy z\n", stdout.String())
+}
+
+func TestExecPrintf(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "strings",
+			script:  `printf '%s|%5s|%-5s|%.2s\n' a b c defg`,
+			wantOut: "a|    b|c    |de\n",
+		},
+		{
+			name:    "numbers",
+			script:  `printf '%d %5.2f %x %X %o %05d %+d %i\n' 42 3.14159 255 255 8 42 7 0x10`,
+			wantOut: "42  3.14 ff FF 10 00042 +7 16\n",
+		},
+		{
+			name:    "recycling",
+			script:  `printf '%s=%s\n' a 1 b 2 c`,
+			wantOut: "a=1\nb=2\nc=\n",
+		},
+		{
+			name:    "star width",
+			script:  `printf '[%*d]\n' 4 7`,
+			wantOut: "[   7]\n",
+		},
+		{
+			name:    "escapes in the format",
+			script:  `printf 'a\tb\101\x42é\n'`,
+			wantOut: "a\tbABé\n",
+		},
+		{
+			name:    "b conversion",
+			script:  `printf '%b|%b\n' 'x\ty' 'a\0101\cignored' more`,
+			wantOut: "x\ty|aA",
+		},
+		{
+			name:    "q conversion",
+			script:  `printf '%q %q %q\n' "a b" '' "it's"`,
+			wantOut: "a\\ b '' it\\'s\n",
+		},
+		{
+			name:    "character constants",
+			script:  `printf '%d %c\n' "'A" hello`,
+			wantOut: "65 h\n",
+		},
+		{
+			name:       "invalid number",
+			script:     `printf '%d,' 1 x 3`,
+			wantOut:    "1,0,3,",
+			wantStatus: 1,
+		},
+		{
+			name:    "percent",
+			script:  `printf '100%%\n'`,
+			wantOut: "100%\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+		})
+	}
+}
+
+func TestExecEcho(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "plain",
+			script:  `echo 'a\tb' c`,
+			wantOut: "a\\tb c\n",
+		},
+		{
+			name:    "combined flags",
+			script:  `echo -ne 'a\tb'`,
+			wantOut: "a\tb",
+		},
+		{
+			name:    "separate flags",
+			script:  `echo -n -e 'x\n'`,
+			wantOut: "x\n",
+		},
+		{
+			name:    "last flag wins",
+			script:  `echo -eE 'a\tb'`,
+			wantOut: "a\\tb\n",
+		},
+		{
+			name:    "not a flag",
+			script:  `echo -nx -n`,
+			wantOut: "-nx -n\n",
+		},
+		{
+			name:    "all escapes",
+			script:  `echo -e '\a\b\e\f\v\\\0101\x42\u00e9'`,
+			wantOut: "\a\b\x1b\f\v\\ABé\n",
+		},
+		{
+			name:    "stop output",
+			script:  `echo -e 'a\cb' c`,
+			wantOut: "a",
+		},
+		{
+			name:    "xpg_echo",
+			script:  "shopt -s xpg_echo\necho 'a\\tb'\necho -E 'a\\tb'\nshopt -u xpg_echo\necho 'a\\tb'",
+			wantOut: "a\tb\na\\tb\na\\tb\n",
+		},
+		{
+			name:    "shopt listing",
+			script:  "shopt xpg_echo\nshopt -s xpg_echo\nshopt -q xpg_echo && echo on",
+			wantOut: "xpg_echo       \toff\non\n",
+		},
+		{
+			name:       "shopt invalid name",
+			script:     "shopt -s nope",
+			wantStatus: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, stdout, _ := newShell(t)
+
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+		})
+	}
+}
+
+func TestExecExec(t *testing.T) {
+	dir := t.TempDir()
+
+	sh, stdout, stderr := newShell(t, shell.WithDir(dir))
+	defer sh.Close()
+
+	script := strings.Join([]string{
+		"exec 3>fd3.txt 4>>log.txt",
+		"echo one >&3",
+		"echo two >&3",
+		"sh -c 'echo external >&3'",
+		"sh -c 'echo to stdout >&2' 2>&1",
+		"echo logged 1>&4",
+		"exec 3>&-",
+		"echo closed >&3",
+		"exec echo replaced",
+		"echo not reached",
+	}, "\n")
+	status, err := sh.Exec(context.Background(), script)
+	assert.Equal(t, 0, status)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "to stdout\nreplaced\n", stdout.String())
+	assert.Contains(t, stderr.String(), "3: bad file descriptor")
+
+	data, err := os.ReadFile(filepath.Join(dir, "fd3.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nexternal\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "log.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "logged\n", string(data))
+
+	status, _ = sh.Exec(context.Background(), "exec sh -c 'exit 5'\necho no")
+	assert.Equal(t, 5, status)
+}
+
+func TestExecFindXargs(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.go":      "package a\n// foo\n",
+		"b.txt":     "foo\n",
+		"sub/c.go":  "package c\nvar foo = 1\n",
+		"sub/d.go":  "package d\n",
+		"two words": "foo\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		wantOut    string
+		wantStatus int
+	}{
+		{
+			name:    "find into xargs grep",
+			script:  "find . -name '*.go' | xargs grep foo",
+			wantOut: "./a.go:// foo\n./sub/c.go:var foo = 1\n",
+		},
+		{
+			name:    "print0 and -0",
+			script:  "find . -name 'two*' -print0 | xargs -0 cat",
+			wantOut: "foo\n",
+		},
+		{
+			name:    "exec",
+			script:  `find sub -type f -exec grep -c package {} \;`,
+			wantOut: "1\n1\n",
+		},
+		{
+			name:    "exec batch",
+			script:  "find sub -name '*.go' -exec wc -l {} +",
+			wantOut: " 2 sub/c.go\n 1 sub/d.go\n 3 total\n",
+		},
+		{
+			name:    "replace",
+			script:  "printf 'a.go\\nb.txt\\n' | xargs -I F echo [F]",
+			wantOut: "[a.go]\n[b.txt]\n",
+		},
+		{
+			name:    "parallel",
+			script:  "echo 3 1 2 | xargs -n 1 -P 3 echo | sort",
+			wantOut: "1\n2\n3\n",
+		},
+		{
+			name:       "failing command",
+			script:     "echo a | xargs false",
+			wantStatus: 123,
+		},
+		{
+			name:       "unknown command",
+			script:     "echo a | xargs nosuchcommand",
+			wantStatus: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var external []string
+			handler := func(next shell.ExecFunc) shell.ExecFunc {
+				return func(ctx context.Context, hc shell.HandlerContext, args []string) error {
+					mu.Lock()
+					external = append(external, args[0])
+					mu.Unlock()
+					return next(ctx, hc, args)
+				}
+			}
+
+			sh, stdout, _ := newShell(t, shell.WithDir(dir), shell.WithExecHandler(handler))
+			status, _ := sh.Exec(context.Background(), tt.script)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantOut, stdout.String())
+			// builtins run by find and xargs stay in-process
+			assert.NotContains(t, external, "grep")
+			assert.NotContains(t, external, "cat")
+		})
+	}
+}
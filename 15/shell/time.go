@@ -0,0 +1,199 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultTimeFormat is the bash default extended with the maximum RSS
+const defaultTimeFormat = "\nreal\t%3lR\nuser\t%3lU\nsys\t%3lS\nmaxrss\t%MK"
+
+// posixTimeFormat is the format of "time -p", which ignores TIMEFORMAT
+const posixTimeFormat = "real %2R\nuser %2U\nsys %2S"
+
+// usage accumulates the resources consumed by a timed job
+type usage struct {
+	mu     sync.Mutex // the commands of a pipeline finish concurrently
+	start  time.Time
+	real   time.Duration
+	user   time.Duration
+	sys    time.Duration
+	maxRSS int64 // kilobytes
+
+	builtins int            // builtins is the number of builtins running
+	before   syscall.Rusage // before is the shell usage when they started
+	beforeOK bool
+}
+
+func newUsage() *usage {
+	return &usage{start: time.Now()}
+}
+
+// addProcess adds the resources of an exited external command
+func (u *usage) addProcess(state *os.ProcessState) {
+	if u == nil || state == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.user += state.UserTime()
+	u.sys += state.SystemTime()
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		u.maxRSS = max(u.maxRSS, maxRSSKilobytes(ru))
+	}
+}
+
+// measureBuiltin runs fn and adds the CPU time the shell spent meanwhile.
+// Builtins run in the shell process, so this is the time of the whole
+// process; builtins running concurrently are counted once, from the start
+// of the first to the end of the last.
+func (u *usage) measureBuiltin(fn func()) {
+	if u == nil {
+		fn()
+		return
+	}
+
+	u.beginBuiltin()
+	defer u.endBuiltin()
+	fn()
+}
+
+func (u *usage) beginBuiltin() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.builtins == 0 {
+		u.beforeOK = syscall.Getrusage(syscall.RUSAGE_SELF, &u.before) == nil
+	}
+	u.builtins++
+}
+
+func (u *usage) endBuiltin() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.builtins--
+	var after syscall.Rusage
+	if u.builtins > 0 || !u.beforeOK || syscall.Getrusage(syscall.RUSAGE_SELF, &after) != nil {
+		return
+	}
+	u.user += timevalDuration(after.Utime) - timevalDuration(u.before.Utime)
+	u.sys += timevalDuration(after.Stime) - timevalDuration(u.before.Stime)
+	u.maxRSS = max(u.maxRSS, maxRSSKilobytes(&after))
+}
+
+// stop fixes the elapsed real time
+func (u *usage) stop() {
+	u.real = time.Since(u.start)
+}
+
+// format renders the usage according to a TIMEFORMAT string:
+// %[p][l]R, %[p][l]U and %[p][l]S print the real, user and system time with
+// p (0-3) decimal places, l selecting the MMmSS.FFFs form; %P prints the CPU
+// percentage, %M the maximum resident set size in kilobytes and %% a percent sign.
+func (u *usage) format(format string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == '\\' && i+1 < len(format) {
+			switch format[i+1] {
+			case 'n':
+				sb.WriteByte('\n')
+				i++
+				continue
+			case 't':
+				sb.WriteByte('\t')
+				i++
+				continue
+			}
+		}
+		if c != '%' || i+1 >= len(format) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+		precision, long := 3, false
+		if format[j] >= '0' && format[j] <= '9' {
+			precision = min(int(format[j]-'0'), 3)
+			j++
+		}
+		if j < len(format) && format[j] == 'l' {
+			long = true
+			j++
+		}
+		if j >= len(format) {
+			sb.WriteString(format[i:])
+			break
+		}
+
+		switch format[j] {
+		case 'R':
+			sb.WriteString(formatSeconds(u.real, precision, long))
+		case 'U':
+			sb.WriteString(formatSeconds(u.user, precision, long))
+		case 'S':
+			sb.WriteString(formatSeconds(u.sys, precision, long))
+		case 'P':
+			var pct float64
+			if u.real > 0 {
+				pct = float64(u.user+u.sys) / float64(u.real) * 100
+			}
+			fmt.Fprintf(&sb, "%.2f", pct)
+		case 'M':
+			fmt.Fprintf(&sb, "%d", u.maxRSS)
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteString(format[i : j+1])
+		}
+		i = j
+	}
+
+	return sb.String()
+}
+
+// reportTime prints the usage of a timed job to stderr using TIMEFORMAT,
+// or the POSIX format for "time -p"
+func (s *Shell) reportTime(u *usage, posix bool) {
+	u.stop()
+	format, ok := s.env.Lookup("TIMEFORMAT")
+	switch {
+	case posix:
+		format = posixTimeFormat
+	case !ok:
+		format = defaultTimeFormat
+	}
+	if format == "" {
+		return
+	}
+	fmt.Fprintln(s.stderr, u.format(format))
+}
+
+func formatSeconds(d time.Duration, precision int, long bool) string {
+	secs := d.Seconds()
+	if !long {
+		return fmt.Sprintf("%.*f", precision, secs)
+	}
+	mins := int(secs / 60)
+	secs -= float64(mins * 60)
+	return fmt.Sprintf("%dm%.*fs", mins, precision, secs)
+}
+
+func timevalDuration(tv syscall.Timeval) time.Duration {
+	return time.Duration(tv.Nano())
+}
+
+// maxRSSKilobytes normalizes ru_maxrss, which is in bytes on macOS
+func maxRSSKilobytes(ru *syscall.Rusage) int64 {
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss) / 1024
+	}
+	return int64(ru.Maxrss)
+}
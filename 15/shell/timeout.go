@@ -0,0 +1,181 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"minishell/internal/builtins"
+	"minishell/internal/models"
+	"minishell/internal/signals"
+)
+
+// timedOutStatus is the exit status of a command stopped by timeout,
+// the same as in coreutils
+const timedOutStatus = 124
+
+const timeoutUsage = "usage: timeout [-s SIGNAL] [-k DURATION] [--foreground] [--preserve-status] DURATION command [arg ...]"
+
+// timeoutOptions tells runExternal how to stop a command that ran out of time
+type timeoutOptions struct {
+	signal     syscall.Signal
+	killAfter  time.Duration // killAfter is the grace period before SIGKILL
+	foreground bool          // foreground keeps the command in the shell's process group
+	preserve   bool          // preserve returns the command's status instead of 124
+}
+
+// defaultKillAfter is the grace period used when -k is not given
+const defaultKillAfter = 5 * time.Second
+
+// apply makes the command run in its own process group, signalled when the
+// context expires and killed if it is still alive after the grace period.
+// The returned stop must be called once Wait returns, so that the kill does
+// not hit another group that reuses the ID.
+func (to *timeoutOptions) apply(execCmd *exec.Cmd) (stop func()) {
+	if to.foreground {
+		execCmd.Cancel = func() error {
+			return execCmd.Process.Signal(to.signal)
+		}
+		execCmd.WaitDelay = to.killAfter
+		return func() {}
+	}
+
+	// Wait returns only after Cancel did, so stop sees the timer it started
+	var kill *time.Timer
+	execCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	execCmd.Cancel = func() error {
+		pgid := execCmd.Process.Pid
+		err := syscall.Kill(-pgid, to.signal)
+		if to.signal != syscall.SIGKILL {
+			kill = time.AfterFunc(to.killAfter, func() {
+				syscall.Kill(-pgid, syscall.SIGKILL)
+			})
+		}
+		return err
+	}
+	// Wait returns once the group is killed even if a grandchild keeps our pipes open
+	execCmd.WaitDelay = to.killAfter + time.Second
+	return func() {
+		if kill != nil {
+			kill.Stop()
+		}
+	}
+}
+
+// runTimeout implements "timeout DURATION cmd": the command gets a context
+// with a deadline and the exit status is 124 if the deadline was hit
+func (s *Shell) runTimeout(ctx context.Context, cmd models.Command, stdio builtins.IO, job int, u *usage) error {
+	to, duration, inner, err := parseTimeout(cmd)
+	if err != nil {
+		return &builtins.StatusError{Status: 125, Err: err}
+	}
+
+	tctx := ctx
+	if duration > 0 {
+		var cancel context.CancelFunc
+		tctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	err = s.runCommand(tctx, inner, stdio, job, u, to)
+	if !errors.Is(tctx.Err(), context.DeadlineExceeded) || ctx.Err() != nil {
+		return err
+	}
+
+	if to.preserve && !errors.Is(err, context.DeadlineExceeded) {
+		// the status the command exited with, 128+15 if SIGTERM killed it
+		return err
+	}
+	status := timedOutStatus
+	if to.signal == syscall.SIGKILL {
+		status = 128 + int(syscall.SIGKILL)
+	}
+	if to.preserve {
+		// a builtin stopped by the deadline has no status of its own
+		status = 128 + int(to.signal)
+	}
+	return &builtins.StatusError{Status: status}
+}
+
+// parseTimeout splits the timeout arguments into its options, the duration
+// and the command to run; redirections are already applied by the caller
+func parseTimeout(cmd models.Command) (*timeoutOptions, time.Duration, models.Command, error) {
+	to := &timeoutOptions{signal: syscall.SIGTERM, killAfter: defaultKillAfter}
+	args := cmd.Args
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		arg := args[0]
+		switch {
+		case arg == "--foreground":
+			to.foreground = true
+		case arg == "--preserve-status":
+			to.preserve = true
+		case arg == "-s" || arg == "--signal":
+			if len(args) < 2 {
+				return nil, 0, cmd, fmt.Errorf("timeout: %s: option requires an argument", arg)
+			}
+			sig, err := signals.Parse(args[1])
+			if err != nil {
+				return nil, 0, cmd, fmt.Errorf("timeout: %w", err)
+			}
+			to.signal = sig
+			args = args[1:]
+		case arg == "-k" || arg == "--kill-after":
+			if len(args) < 2 {
+				return nil, 0, cmd, fmt.Errorf("timeout: %s: option requires an argument", arg)
+			}
+			d, err := parseDuration(args[1])
+			if err != nil {
+				return nil, 0, cmd, fmt.Errorf("timeout: %w", err)
+			}
+			to.killAfter = d
+			args = args[1:]
+		default:
+			return nil, 0, cmd, fmt.Errorf("timeout: unknown option: %s", arg)
+		}
+		args = args[1:]
+	}
+
+	if len(args) < 2 {
+		return nil, 0, cmd, fmt.Errorf("%s", timeoutUsage)
+	}
+	duration, err := parseDuration(args[0])
+	if err != nil {
+		return nil, 0, cmd, fmt.Errorf("timeout: %w", err)
+	}
+
+	inner := models.Command{Name: args[1], Args: args[2:]}
+	return to, duration, inner, nil
+}
+
+// parseDuration accepts coreutils durations (a number with an optional
+// s, m, h or d suffix) as well as Go durations like 1m30s
+func parseDuration(s string) (time.Duration, error) {
+	unit := time.Second
+	num := s
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 's':
+			num = s[:n-1]
+		case 'm':
+			unit, num = time.Minute, s[:n-1]
+		case 'h':
+			unit, num = time.Hour, s[:n-1]
+		case 'd':
+			unit, num = 24*time.Hour, s[:n-1]
+		}
+	}
+
+	if f, err := strconv.ParseFloat(num, 64); err == nil && f >= 0 {
+		return time.Duration(f * float64(unit)), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid time interval: %s", s)
+}
@@ -0,0 +1,111 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"minishell/internal/builtins"
+	"minishell/internal/models"
+)
+
+// defaultPS4 prefixes the commands printed by set -x when PS4 is unset
+const defaultPS4 = "+ "
+
+// traceLog writes one JSON record per executed command
+type traceLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// traceRecord describes a command run by the shell
+type traceRecord struct {
+	Argv         []string        `json:"argv"`
+	Cwd          string          `json:"cwd"`
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	Exit         int             `json:"exit"`
+	PID          int             `json:"pid,omitempty"` // PID is 0 for builtins
+	Redirections []traceRedirect `json:"redirections,omitempty"`
+}
+
+// traceRedirect is a redirection of a traced command
+type traceRedirect struct {
+	FD     int    `json:"fd"`
+	Op     string `json:"op"`
+	Target string `json:"target"`
+}
+
+// beginTrace starts the record of cmd, or returns nil when no trace is
+// written
+func (s *Shell) beginTrace(cmd models.Command) *traceRecord {
+	if s.trace == nil {
+		return nil
+	}
+
+	rec := &traceRecord{
+		Argv:  append([]string{cmd.Name}, cmd.Args...),
+		Cwd:   s.builtin.Dir(),
+		Start: time.Now(),
+	}
+	for _, r := range cmd.Redirects {
+		rec.Redirections = append(rec.Redirections, traceRedirect(r))
+	}
+	return rec
+}
+
+// started records the PID of the process running the command
+func (rec *traceRecord) started(pid int) {
+	if rec != nil {
+		rec.PID = pid
+	}
+}
+
+// endTrace completes rec with the result of the command and writes it
+func (s *Shell) endTrace(rec *traceRecord, err error) {
+	if rec == nil {
+		return
+	}
+	rec.End = time.Now()
+	rec.Exit = builtins.ExitStatus(err)
+
+	s.trace.mu.Lock()
+	defer s.trace.mu.Unlock()
+	if err := s.trace.enc.Encode(rec); err != nil {
+		fmt.Fprintln(s.stderr, "trace:", err)
+	}
+}
+
+// traceCommand prints a command about to run, as set -x does
+func (s *Shell) traceCommand(cmd models.Command) {
+	if !s.builtin.Option("xtrace") {
+		return
+	}
+
+	ps4, ok := s.env.Lookup("PS4")
+	if !ok {
+		ps4 = defaultPS4
+	}
+
+	words := make([]string, 0, len(cmd.Args)+1)
+	for _, arg := range append([]string{cmd.Name}, cmd.Args...) {
+		words = append(words, quoteWord(arg))
+	}
+	fmt.Fprintln(s.stderr, ps4+strings.Join(words, " "))
+}
+
+// quoteWord quotes a word for display when it would not read back as one
+func quoteWord(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\n'\"\\$*?[|&;<>") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// newTraceLog returns a traceLog writing to w
+func newTraceLog(w io.Writer) *traceLog {
+	return &traceLog{enc: json.NewEncoder(w)}
+}
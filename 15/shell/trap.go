@@ -0,0 +1,94 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"minishell/internal/signals"
+)
+
+// trapState delivers the signals that have a trap to the shell loop, which
+// runs their actions between command lines
+type trapState struct {
+	ch       chan os.Signal
+	notified []syscall.Signal // notified are the signals ch receives
+	running  bool             // running is set while an action runs
+}
+
+// syncTraps makes the shell receive exactly the signals that have a trap
+func (s *Shell) syncTraps() {
+	sigs := s.builtin.TrappedSignals()
+	if slices.Equal(sigs, s.traps.notified) {
+		return
+	}
+
+	if s.traps.ch == nil {
+		s.traps.ch = make(chan os.Signal, 8)
+	}
+	signal.Stop(s.traps.ch)
+	s.traps.notified = sigs
+	if len(sigs) == 0 {
+		return
+	}
+
+	notify := make([]os.Signal, len(sigs))
+	for i, sig := range sigs {
+		notify[i] = sig
+	}
+	signal.Notify(s.traps.ch, notify...)
+}
+
+// stopTraps stops receiving signals for traps
+func (s *Shell) stopTraps() {
+	if s.traps.ch != nil {
+		signal.Stop(s.traps.ch)
+	}
+	s.traps.notified = nil
+}
+
+// afterLine picks up traps set by the last line and runs the actions of
+// the signals that arrived meanwhile. It reports whether an action called
+// exit, and with which status.
+func (s *Shell) afterLine(ctx context.Context) (int, bool) {
+	s.syncTraps()
+	if s.traps.ch == nil {
+		return 0, false
+	}
+
+	for {
+		select {
+		case sig := <-s.traps.ch:
+			name := signals.Name(sig.(syscall.Signal))
+			if code, exited := s.runTrap(ctx, name); exited {
+				return code, true
+			}
+		default:
+			return 0, false
+		}
+	}
+}
+
+// runTrap runs the action trapped for a condition, keeping $? unless the
+// action exits. The EXIT trap runs only once.
+func (s *Shell) runTrap(ctx context.Context, name string) (int, bool) {
+	action, ok := s.builtin.TrapAction(name)
+	if !ok || action == "" || s.traps.running {
+		return 0, false
+	}
+	if name == "EXIT" {
+		s.builtin.ResetTrap(name)
+	}
+
+	s.traps.running = true
+	defer func() { s.traps.running = false }()
+
+	status := s.status
+	code, exited, _ := s.execLines(ctx, action)
+	if !exited {
+		s.status = status
+	}
+	return code, exited
+}
@@ -0,0 +1,75 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"minishell/internal/builtins"
+	"minishell/internal/builtins/find"
+	"minishell/internal/builtins/xargs"
+	"minishell/internal/models"
+)
+
+// runXargs implements xargs. The command lines it builds are run like
+// typed ones, so that e.g. "xargs grep foo" uses the builtin grep.
+func (s *Shell) runXargs(ctx context.Context, cmd models.Command, stdio builtins.IO, job int, u *usage) error {
+	cfg, err := xargs.ParseConfig(cmd.Args...)
+	if err != nil {
+		return &builtins.StatusError{Status: 1, Err: fmt.Errorf("xargs: %w", err)}
+	}
+
+	// the input belongs to xargs, the commands read nothing
+	child := stdio
+	child.Stdin = nil
+	status, err := xargs.Run(ctx, stdio.Stdin, cfg, s.commandRunner(child, job, u))
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return &builtins.StatusError{Status: status, Err: fmt.Errorf("xargs: %w", err)}
+	}
+	if status != 0 {
+		return &builtins.StatusError{Status: status}
+	}
+	return nil
+}
+
+// runFind implements find; the commands of -exec are run like typed ones
+func (s *Shell) runFind(ctx context.Context, cmd models.Command, stdio builtins.IO, job int, u *usage) error {
+	cfg, err := find.ParseConfig(cmd.Args...)
+	if err != nil {
+		return &builtins.StatusError{Status: 1, Err: fmt.Errorf("find: %w", err)}
+	}
+
+	env := find.Env{
+		Dir:    s.builtin.Dir(),
+		Stdout: stdio.Stdout,
+		Run:    s.commandRunner(stdio, job, u),
+	}
+	if err := find.Find(ctx, cfg, env); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("find: %w", err)
+	}
+	return nil
+}
+
+// commandRunner returns a function running commands through the shell's
+// builtins and exec handlers with the given streams. Failures the command
+// did not explain itself are reported; the exit status is returned.
+func (s *Shell) commandRunner(stdio builtins.IO, job int, u *usage) func(ctx context.Context, args []string) int {
+	return func(ctx context.Context, args []string) int {
+		err := s.runCommand(ctx, models.Command{Name: args[0], Args: args[1:]}, stdio, job, u, nil)
+
+		var exitErr *exec.ExitError
+		var statusErr *builtins.StatusError
+		explained := errors.As(err, &exitErr) || (errors.As(err, &statusErr) && statusErr.Err == nil)
+		if err != nil && !explained {
+			s.reportError(err)
+		}
+		return builtins.ExitStatus(err)
+	}
+}
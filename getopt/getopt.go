@@ -0,0 +1,141 @@
+// Package getopt parses command line options like getopt_long of GNU libc.
+// Short options may be bundled ("-in") and take their value attached
+// ("-A3") or as the next argument ("-A 3"); long options take it after "="
+// or as the next argument and may be abbreviated to any unique prefix.
+// Options and operands may be mixed, "--" ends the options and "-" is an
+// operand.
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Option describes one option of a command
+type Option struct {
+	Short  byte   // Short is the letter of -x, 0 for none
+	Long   string // Long is the name of --name, "" for none
+	HasArg bool   // HasArg tells whether the option takes a value
+}
+
+// Handler is called for each option in the order of the arguments, with
+// its value if it takes one
+type Handler func(opt Option, value string) error
+
+// Parse parses args with the given options, calls handle for each option
+// found and returns the operands. Errors are those of getopt_long, e.g.
+// "invalid option -- 'z'", or the ones handle returns.
+func Parse(args []string, options []Option, handle Handler) ([]string, error) {
+	var operands []string
+
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+
+		switch {
+		case arg == "--":
+			return append(operands, args...), nil
+		case strings.HasPrefix(arg, "--"):
+			rest, err := parseLong(arg[2:], args, options, handle)
+			if err != nil {
+				return nil, err
+			}
+			args = rest
+		case len(arg) > 1 && arg[0] == '-':
+			rest, err := parseShort(arg[1:], args, options, handle)
+			if err != nil {
+				return nil, err
+			}
+			args = rest
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return operands, nil
+}
+
+// parseShort handles a bundle of short options and returns the arguments
+// left after it
+func parseShort(bundle string, args []string, options []Option, handle Handler) ([]string, error) {
+	for i := 0; i < len(bundle); i++ {
+		opt, ok := findShort(options, bundle[i])
+		if !ok {
+			return nil, fmt.Errorf("invalid option -- '%c'", bundle[i])
+		}
+		if !opt.HasArg {
+			if err := handle(opt, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// the value is the rest of the bundle or the next argument
+		value := bundle[i+1:]
+		if value == "" {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("option requires an argument -- '%c'", bundle[i])
+			}
+			value, args = args[0], args[1:]
+		}
+		return args, handle(opt, value)
+	}
+	return args, nil
+}
+
+// parseLong handles the long option arg, without its dashes, and returns
+// the arguments left after it
+func parseLong(arg string, args []string, options []Option, handle Handler) ([]string, error) {
+	name, value, hasValue := strings.Cut(arg, "=")
+	opt, err := findLong(options, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !opt.HasArg && hasValue:
+		return nil, fmt.Errorf("option '--%s' doesn't allow an argument", opt.Long)
+	case opt.HasArg && !hasValue:
+		if len(args) == 0 {
+			return nil, fmt.Errorf("option '--%s' requires an argument", opt.Long)
+		}
+		value, args = args[0], args[1:]
+	}
+	return args, handle(opt, value)
+}
+
+// findShort returns the option with the letter c
+func findShort(options []Option, c byte) (Option, bool) {
+	for _, opt := range options {
+		if opt.Short != 0 && opt.Short == c {
+			return opt, true
+		}
+	}
+	return Option{}, false
+}
+
+// findLong returns the option named name or, failing that, the only one
+// whose name starts with it
+func findLong(options []Option, name string) (Option, error) {
+	var matches []Option
+	for _, opt := range options {
+		if opt.Long == "" || name == "" || !strings.HasPrefix(opt.Long, name) {
+			continue
+		}
+		if opt.Long == name {
+			return opt, nil
+		}
+		matches = append(matches, opt)
+	}
+
+	switch len(matches) {
+	case 0:
+		return Option{}, fmt.Errorf("unrecognized option '--%s'", name)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, opt := range matches {
+		names[i] = "'--" + opt.Long + "'"
+	}
+	return Option{}, fmt.Errorf("option '--%s' is ambiguous; possibilities: %s", name, strings.Join(names, " "))
+}
@@ -0,0 +1,160 @@
+package getopt_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getopt"
+)
+
+var options = []getopt.Option{
+	{Short: 'A', Long: "after-context", HasArg: true},
+	{Short: 'c', Long: "count"},
+	{Short: 'i', Long: "ignore-case"},
+	{Short: 'n', Long: "line-number"},
+	{Long: "context", HasArg: true},
+	{Short: 'x'},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantOpts     []string
+		wantOperands []string
+		wantErr      string
+	}{
+		{
+			name:         "separate flags",
+			args:         []string{"-i", "-n", "pat", "file"},
+			wantOpts:     []string{"i", "n"},
+			wantOperands: []string{"pat", "file"},
+		},
+		{
+			name:     "bundled flags",
+			args:     []string{"-inx"},
+			wantOpts: []string{"i", "n", "x"},
+		},
+		{
+			name:     "attached value",
+			args:     []string{"-A3"},
+			wantOpts: []string{"A=3"},
+		},
+		{
+			name:         "separate value",
+			args:         []string{"-A", "-1", "pat"},
+			wantOpts:     []string{"A=-1"},
+			wantOperands: []string{"pat"},
+		},
+		{
+			name:     "value after bundled flags",
+			args:     []string{"-icA", "2"},
+			wantOpts: []string{"i", "c", "A=2"},
+		},
+		{
+			name:     "long options",
+			args:     []string{"--ignore-case", "--after-context=4", "--context", "5"},
+			wantOpts: []string{"i", "A=4", "context=5"},
+		},
+		{
+			name:     "abbreviated long option",
+			args:     []string{"--ign", "--cou"},
+			wantOpts: []string{"i", "c"},
+		},
+		{
+			name:     "exact name before prefixes",
+			args:     []string{"--context=1"},
+			wantOpts: []string{"context=1"},
+		},
+		{
+			name:         "options after operands",
+			args:         []string{"pat", "-c", "file"},
+			wantOpts:     []string{"c"},
+			wantOperands: []string{"pat", "file"},
+		},
+		{
+			name:         "end of options",
+			args:         []string{"-i", "--", "-n", "--count"},
+			wantOpts:     []string{"i"},
+			wantOperands: []string{"-n", "--count"},
+		},
+		{
+			name:         "dash is an operand",
+			args:         []string{"-", "-c"},
+			wantOpts:     []string{"c"},
+			wantOperands: []string{"-"},
+		},
+		{
+			name:    "invalid option",
+			args:    []string{"-iz"},
+			wantErr: "invalid option -- 'z'",
+		},
+		{
+			name:    "missing value",
+			args:    []string{"pat", "-A"},
+			wantErr: "option requires an argument -- 'A'",
+		},
+		{
+			name:    "unrecognized long option",
+			args:    []string{"--nope"},
+			wantErr: "unrecognized option '--nope'",
+		},
+		{
+			name:    "missing long value",
+			args:    []string{"--after-context"},
+			wantErr: "option '--after-context' requires an argument",
+		},
+		{
+			name:    "unexpected long value",
+			args:    []string{"--count=2"},
+			wantErr: "option '--count' doesn't allow an argument",
+		},
+		{
+			name:    "ambiguous long option",
+			args:    []string{"--co"},
+			wantErr: "option '--co' is ambiguous; possibilities: '--count' '--context'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []string
+			handle := func(opt getopt.Option, value string) error {
+				name := string(opt.Short)
+				if opt.Short == 0 {
+					name = opt.Long
+				}
+				if opt.HasArg {
+					name += "=" + value
+				}
+				opts = append(opts, name)
+				return nil
+			}
+
+			operands, err := getopt.Parse(tt.args, options, handle)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOpts, opts)
+			assert.Equal(t, tt.wantOperands, operands)
+		})
+	}
+}
+
+func TestParseHandlerError(t *testing.T) {
+	errBad := errors.New("bad value")
+	handle := func(opt getopt.Option, value string) error {
+		if value == "x" {
+			return fmt.Errorf("%s: %w", value, errBad)
+		}
+		return nil
+	}
+
+	_, err := getopt.Parse([]string{"-A1", "-Ax", "-c"}, options, handle)
+	assert.ErrorIs(t, err, errBad)
+}